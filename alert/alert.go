@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is the lifecycle state of an alert.
+type State int
+
+// Alert states.
+const (
+	Inactive State = iota // The condition has not been observed
+	Pending               // The condition has been observed but the alert has not fired yet
+	Firing                // The alert has fired
+	Resolved              // The alert fired previously and the condition no longer holds
+)
+
+// String returns a string representation of the state.
+func (s State) String() string {
+	switch s {
+	case Inactive:
+		return "inactive"
+	case Pending:
+		return "pending"
+	case Firing:
+		return "firing"
+	case Resolved:
+		return "resolved"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// Alert describes the state of a rule for a particular connection or folder.
+type Alert struct {
+	Rule      string
+	Condition Condition
+	Group     string
+	From      string
+	To        string
+	FromSite  string // Source site, for alerts that apply to a site pair
+	ToSite    string // Destination site, for alerts that apply to a site pair
+	Folder    string // Empty for alerts that apply to the whole connection
+	State     State
+	Value     uint      // Most recently observed backlog
+	Errors    int       // Number of consecutive failed queries
+	Err       error     // Most recent query error
+	Since     time.Time // Time at which the condition was first observed
+	Fired     time.Time // Time at which the alert fired
+	Resolved  time.Time // Time at which the alert was resolved
+	Updated   time.Time // Time of the most recent observation
+}
+
+// SitePair reports whether the alert applies to the combined backlog of a
+// pair of sites rather than to an individual connection.
+func (a Alert) SitePair() bool {
+	return a.FromSite != "" || a.ToSite != ""
+}
+
+// String returns a description of the alert.
+func (a Alert) String() string {
+	subject := fmt.Sprintf("%s backlog from %s to %s", a.Group, a.From, a.To)
+	if a.SitePair() {
+		subject = fmt.Sprintf("Backlog from site %s to site %s", a.FromSite, a.ToSite)
+	}
+	if a.Folder != "" {
+		subject = fmt.Sprintf("%s (%s)", subject, a.Folder)
+	}
+	switch a.Condition {
+	case Errors:
+		if a.Err != nil {
+			return fmt.Sprintf("%s: %s: %d consecutive errors: %v", a.Rule, subject, a.Errors, a.Err)
+		}
+		return fmt.Sprintf("%s: %s: %d consecutive errors", a.Rule, subject, a.Errors)
+	default:
+		return fmt.Sprintf("%s: %s: %d since %s", a.Rule, subject, a.Value, a.Since.Format(time.RFC3339))
+	}
+}
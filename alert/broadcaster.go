@@ -0,0 +1,46 @@
+package alert
+
+import "sync"
+
+// broadcaster broadcasts alert state changes to a set of listeners.
+type broadcaster struct {
+	mutex     sync.RWMutex
+	listeners []chan<- Alert
+	closed    bool
+}
+
+func (bc *broadcaster) Listen() <-chan Alert {
+	ch := make(chan Alert, updateChanSize)
+	bc.mutex.Lock()
+	if !bc.closed {
+		bc.listeners = append(bc.listeners, ch)
+	} else {
+		close(ch)
+	}
+	bc.mutex.Unlock()
+	return ch
+}
+
+func (bc *broadcaster) Broadcast(a Alert) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	for _, listener := range bc.listeners {
+		listener <- a
+	}
+}
+
+func (bc *broadcaster) Close() {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	if bc.closed {
+		return
+	}
+	bc.closed = true
+
+	for _, ch := range bc.listeners {
+		close(ch)
+	}
+	bc.listeners = nil
+}
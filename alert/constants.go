@@ -0,0 +1,14 @@
+package alert
+
+import "errors"
+
+const updateChanSize = 16
+
+var (
+	// ErrInvalidRule is returned when a rule is missing required values or has
+	// an unknown condition.
+	ErrInvalidRule = errors.New("The alerting rule is invalid.")
+
+	// ErrDuplicateRule is returned when more than one rule has the same name.
+	ErrDuplicateRule = errors.New("The alerting rule name is not unique.")
+)
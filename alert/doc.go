@@ -0,0 +1,35 @@
+// Package alert evaluates alerting rules against DFSR backlog updates.
+//
+// An Engine consumes the updates produced by a monitor.Monitor and evaluates
+// each backlog against a set of rules. Each rule applies to the replication
+// groups, connections and folders that it matches and has one of the
+// following conditions:
+//
+//   Threshold   The backlog is above a threshold
+//   Sustained   The backlog has been above a threshold for a duration
+//   Errors      A number of consecutive backlog queries have failed
+//   Stalled     The backlog has not decreased for a duration
+//
+// Each combination of rule and matched connection (or folder) is tracked as
+// an alert that moves from pending to firing to resolved. Listeners are only
+// notified when an alert changes state, so a backlog that remains high does
+// not produce a notification for every update:
+//
+//   engine, err := alert.New(alert.DefaultRules, mon.Listen(16))
+//   if err != nil {
+//     return err
+//   }
+//   for a := range engine.Listen() {
+//     log.Printf("%s: %s", a.State, a)
+//   }
+//
+// Backlogs are expected to grow while a connection's schedule does not allow
+// replication, so backlog conditions are suspended for unscheduled
+// connections unless a rule sets Unscheduled. Only schedules kept in UTC are
+// considered, because the time zone of the receiving member is not known.
+//
+// Rules may match connections by the sites of their computers. A rule that
+// sets PerSitePair evaluates the combined backlog of all matched connections
+// between each pair of sites, which is useful when backlogs accumulate on
+// slow links between sites rather than on individual servers.
+package alert
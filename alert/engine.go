@@ -0,0 +1,474 @@
+package alert
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/helper"
+	"gopkg.in/dfsr.v0/monitor"
+)
+
+// key identifies an alert.
+type key struct {
+	Rule     string
+	Group    string
+	From     string
+	To       string
+	FromSite string
+	ToSite   string
+	Folder   string
+}
+
+// sitePair reports whether the key identifies a site pair alert.
+func (k *key) sitePair() bool {
+	return k.FromSite != "" || k.ToSite != ""
+}
+
+// tracker holds the evaluation state of an alert.
+type tracker struct {
+	alert    Alert
+	observed bool      // Has a backlog value been observed?
+	last     uint      // Last observed backlog value
+	progress time.Time // Last time the backlog decreased, used by Stalled
+	failing  time.Time // Time of the first of the consecutive errors, used by Errors
+}
+
+// connection identifies a one-way connection within a replication group.
+type connection struct {
+	Group string
+	From  string
+	To    string
+}
+
+// Engine evaluates alerting rules against backlog updates.
+type Engine struct {
+	rules []Rule
+	ch    <-chan *monitor.Update
+	bc    broadcaster
+
+	mutex    sync.Mutex
+	trackers map[key]*tracker
+}
+
+// New returns a new alerting engine that evaluates the given rules against
+// the provided backlog updates. The engine will function until the update
+// channel is closed, at which point the channels returned by Listen are
+// closed as well.
+//
+// If updates is nil the engine does not consume updates on its own, and
+// backlogs must be supplied by calling Evaluate.
+func New(rules []Rule, updates <-chan *monitor.Update) (*Engine, error) {
+	if err := validate(rules); err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		rules:    append([]Rule(nil), rules...),
+		ch:       updates,
+		trackers: make(map[key]*tracker),
+	}
+	if updates != nil {
+		go e.run()
+	}
+	return e, nil
+}
+
+// Listen returns a channel on which alert state changes will be broadcast.
+// An alert is only broadcast when it becomes pending, fires or is resolved.
+func (e *Engine) Listen() <-chan Alert {
+	return e.bc.Listen()
+}
+
+// Alerts returns the alerts that are currently pending or firing.
+func (e *Engine) Alerts() (alerts []Alert) {
+	e.mutex.Lock()
+	for _, t := range e.trackers {
+		if t.alert.State == Pending || t.alert.State == Firing {
+			alerts = append(alerts, t.alert)
+		}
+	}
+	e.mutex.Unlock()
+
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := &alerts[i], &alerts[j]
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.FromSite != b.FromSite {
+			return a.FromSite < b.FromSite
+		}
+		if a.ToSite != b.ToSite {
+			return a.ToSite < b.ToSite
+		}
+		return a.Folder < b.Folder
+	})
+	return
+}
+
+// Evaluate evaluates the rules against the given backlog and returns the
+// alerts that changed state as a result. The backlog is considered to have
+// been observed when its call completed. Rules that are evaluated per site
+// pair are ignored, see EvaluateSites.
+func (e *Engine) Evaluate(backlog *core.Backlog) (changes []Alert) {
+	now := backlog.Call.End
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	group := groupOf(backlog)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.PerSitePair || !r.Match.connection(group, backlog) {
+			continue
+		}
+
+		k := key{Rule: r.Name, Group: group, From: backlog.From, To: backlog.To}
+
+		if r.Condition == Errors {
+			if backlog.Err != nil && helper.IsCancellationErr(backlog.Err) {
+				continue // Cancelled queries say nothing about the connection
+			}
+			if t := e.tracker(r, k); t.evaluateErrors(r, backlog.Err, now) {
+				changes = append(changes, t.alert)
+				t.settle()
+			}
+			continue
+		}
+
+		if backlog.Err != nil || len(backlog.Folders) == 0 {
+			continue // No data, so the state of backlog alerts is unknown
+		}
+
+		if backlog.Unscheduled && !r.Unscheduled {
+			e.pause(k, backlog, now)
+			continue
+		}
+
+		if !r.perFolder() {
+			if t := e.tracker(r, k); t.evaluateBacklog(r, backlog.Sum(), now) {
+				changes = append(changes, t.alert)
+				t.settle()
+			}
+			continue
+		}
+
+		for f := range backlog.Folders {
+			fb := &backlog.Folders[f]
+			if fb.Folder == nil || fb.Backlog < 0 || !r.Match.folder(fb.Folder.Name) {
+				continue
+			}
+			fk := k
+			fk.Folder = fb.Folder.Name
+			if t := e.tracker(r, fk); t.evaluateBacklog(r, uint(fb.Backlog), now) {
+				changes = append(changes, t.alert)
+				t.settle()
+			}
+		}
+	}
+
+	return
+}
+
+// Prune resolves and removes the alerts of all connections that are not
+// present in the given set of backlogs, which is typically the complete set
+// of backlogs from an update. It returns the alerts that were resolved.
+func (e *Engine) Prune(backlogs []*core.Backlog) (changes []Alert) {
+	seen := make(map[connection]bool, len(backlogs))
+	for _, backlog := range backlogs {
+		seen[connectionOf(backlog)] = true
+	}
+
+	now := time.Now()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for k, t := range e.trackers {
+		if k.sitePair() || seen[connection{Group: k.Group, From: k.From, To: k.To}] {
+			continue
+		}
+		if t.alert.State == Firing {
+			t.alert.State = Resolved
+			t.alert.Resolved = now
+			changes = append(changes, t.alert)
+		}
+		delete(e.trackers, k)
+	}
+
+	return
+}
+
+// EvaluateSites evaluates the rules that set PerSitePair against the combined
+// backlog of each pair of sites and returns the alerts that changed state as
+// a result. The given backlogs must be the complete set of backlogs from an
+// update. The alerts of site pairs that are no longer present are resolved
+// and removed.
+//
+// A site pair is evaluated when at least one of its matched connections
+// returned a backlog. The evaluation of a site pair is suspended while the
+// schedules of all of its connections disallow replication, unless the rule
+// sets Unscheduled.
+func (e *Engine) EvaluateSites(backlogs []*core.Backlog) (changes []Alert) {
+	now := time.Now()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	seen := make(map[key]bool)
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.PerSitePair {
+			continue
+		}
+
+		var scheduled []*core.Backlog
+		present := make(map[core.SitePair]bool)
+		for _, backlog := range backlogs {
+			pair := backlog.Sites()
+			if pair.From == "" || pair.To == "" || !r.Match.connection(groupOf(backlog), backlog) {
+				continue
+			}
+			present[pair] = true
+			if backlog.Err != nil || len(backlog.Folders) == 0 {
+				continue // No data for this connection
+			}
+			if backlog.Unscheduled && !r.Unscheduled {
+				continue
+			}
+			scheduled = append(scheduled, backlog)
+		}
+
+		evaluated := make(map[core.SitePair]bool)
+		for _, sb := range core.SiteBacklogs(scheduled) {
+			evaluated[sb.SitePair] = true
+			k := key{Rule: r.Name, FromSite: sb.From, ToSite: sb.To}
+			if t := e.tracker(r, k); t.evaluateBacklog(r, sb.Backlog, now) {
+				changes = append(changes, t.alert)
+				t.settle()
+			}
+		}
+
+		for pair := range present {
+			k := key{Rule: r.Name, FromSite: pair.From, ToSite: pair.To}
+			seen[k] = true
+			if evaluated[pair] {
+				continue
+			}
+			if t, ok := e.trackers[k]; ok {
+				t.pause(now)
+			}
+		}
+	}
+
+	for k, t := range e.trackers {
+		if !k.sitePair() || seen[k] {
+			continue
+		}
+		if t.alert.State == Firing {
+			t.alert.State = Resolved
+			t.alert.Resolved = now
+			changes = append(changes, t.alert)
+		}
+		delete(e.trackers, k)
+	}
+
+	return
+}
+
+func (e *Engine) run() {
+	defer e.bc.Close()
+
+	for {
+		update, ok := <-e.ch
+		if !ok {
+			return
+		}
+
+		var backlogs []*core.Backlog
+		for backlog := range update.Listen() {
+			backlogs = append(backlogs, backlog)
+			e.publish(e.Evaluate(backlog))
+		}
+
+		// Only prune when the update is complete, otherwise the alerts of
+		// connections that weren't queried before cancellation would be lost.
+		if len(backlogs) == update.Size() {
+			e.publish(e.EvaluateSites(backlogs))
+			e.publish(e.Prune(backlogs))
+		}
+	}
+}
+
+func (e *Engine) publish(changes []Alert) {
+	for _, a := range changes {
+		e.bc.Broadcast(a)
+	}
+}
+
+// pause suspends the evaluation of the backlog alerts for the connection
+// identified by k while its schedule does not allow replication. It must be
+// called while a lock on the engine's mutex is held.
+func (e *Engine) pause(k key, backlog *core.Backlog, now time.Time) {
+	if t, ok := e.trackers[k]; ok {
+		t.pause(now)
+	}
+	for f := range backlog.Folders {
+		if fb := &backlog.Folders[f]; fb.Folder != nil {
+			fk := k
+			fk.Folder = fb.Folder.Name
+			if t, ok := e.trackers[fk]; ok {
+				t.pause(now)
+			}
+		}
+	}
+}
+
+// tracker returns the tracker for the given key, creating it if necessary.
+// It must be called while a lock on the engine's mutex is held.
+func (e *Engine) tracker(r *Rule, k key) *tracker {
+	t, ok := e.trackers[k]
+	if !ok {
+		t = &tracker{
+			alert: Alert{
+				Rule:      r.Name,
+				Condition: r.Condition,
+				Group:     k.Group,
+				From:      k.From,
+				To:        k.To,
+				FromSite:  k.FromSite,
+				ToSite:    k.ToSite,
+				Folder:    k.Folder,
+			},
+		}
+		e.trackers[k] = t
+	}
+	return t
+}
+
+// evaluateBacklog updates the tracker with a backlog observation and reports
+// whether the state of the alert changed.
+func (t *tracker) evaluateBacklog(r *Rule, value uint, now time.Time) (changed bool) {
+	var active, ready bool
+	switch r.Condition {
+	case Threshold:
+		active = value > r.Threshold
+		ready = active
+	case Sustained:
+		active = value > r.Threshold
+		ready = active && t.alert.State != Inactive && now.Sub(t.alert.Since) >= r.Duration
+	case Stalled:
+		if !t.observed || value < t.last || value <= r.Threshold {
+			t.progress = now
+		}
+		active = value > r.Threshold && t.observed && value >= t.last
+		ready = active && now.Sub(t.progress) >= r.Duration
+	}
+
+	t.observed, t.last = true, value
+	t.alert.Value = value
+	t.alert.Updated = now
+
+	since := now
+	if r.Condition == Stalled {
+		since = t.progress
+	}
+	return t.transition(active, ready, since, now)
+}
+
+// pause restarts the evaluation of a backlog alert that has not fired yet, so
+// that time spent outside of the connection's replication window does not
+// count towards its duration. Firing alerts are left as they are.
+func (t *tracker) pause(now time.Time) {
+	t.observed = false
+	t.progress = now
+	if t.alert.State == Pending {
+		t.alert.State = Inactive
+	}
+}
+
+// evaluateErrors updates the tracker with the result of a query and reports
+// whether the state of the alert changed.
+func (t *tracker) evaluateErrors(r *Rule, err error, now time.Time) (changed bool) {
+	if err != nil {
+		if t.alert.Errors == 0 {
+			t.failing = now
+		}
+		t.alert.Errors++
+	} else {
+		t.alert.Errors = 0
+	}
+	t.alert.Err = err
+	t.alert.Updated = now
+
+	// Errors are only taken into account once they have persisted for the
+	// rule's count, so an occasional failed query does not produce a pending
+	// alert. The alert fires as soon as it becomes active.
+	active := t.alert.Errors >= r.Count
+	return t.transition(active, active, t.failing, now)
+}
+
+// transition moves the alert through its lifecycle and reports whether its
+// state changed. An alert that stops being active before it fires returns to
+// the inactive state without a notification.
+func (t *tracker) transition(active, ready bool, since, now time.Time) (changed bool) {
+	if !active {
+		switch t.alert.State {
+		case Firing:
+			t.alert.State = Resolved
+			t.alert.Resolved = now
+			return true
+		case Pending:
+			t.alert.State = Inactive
+		}
+		return false
+	}
+
+	if t.alert.State == Inactive {
+		t.alert.State = Pending
+		t.alert.Since = since
+		t.alert.Fired = time.Time{}
+		t.alert.Resolved = time.Time{}
+		changed = true
+	}
+
+	if ready && t.alert.State != Firing {
+		t.alert.State = Firing
+		t.alert.Fired = now
+		changed = true
+	}
+
+	return
+}
+
+// settle returns a resolved alert to the inactive state after it has been
+// reported.
+func (t *tracker) settle() {
+	if t.alert.State == Resolved {
+		t.alert.State = Inactive
+	}
+}
+
+func connectionOf(backlog *core.Backlog) connection {
+	return connection{Group: groupOf(backlog), From: backlog.From, To: backlog.To}
+}
+
+func groupOf(backlog *core.Backlog) string {
+	if backlog.Group != nil {
+		return backlog.Group.Name
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package alert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/yaml.v2"
+)
+
+// Condition is the condition under which a rule fires.
+type Condition int
+
+// Rule conditions.
+const (
+	// Threshold fires as soon as the backlog is above the rule's threshold.
+	Threshold Condition = iota + 1
+	// Sustained fires when the backlog has been above the rule's threshold for
+	// at least the rule's duration.
+	Sustained
+	// Errors fires when the number of consecutive failed backlog queries
+	// reaches the rule's count. Cancelled queries are ignored.
+	Errors
+	// Stalled fires when a backlog above the rule's threshold has not
+	// decreased for at least the rule's duration.
+	Stalled
+)
+
+// String returns a string representation of the condition.
+func (c Condition) String() string {
+	switch c {
+	case Threshold:
+		return "threshold"
+	case Sustained:
+		return "sustained"
+	case Errors:
+		return "errors"
+	case Stalled:
+		return "stalled"
+	default:
+		return fmt.Sprintf("condition(%d)", int(c))
+	}
+}
+
+// MarshalText returns the string representation of the condition.
+func (c Condition) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText parses a condition from its string representation.
+func (c *Condition) UnmarshalText(text []byte) error {
+	for _, candidate := range []Condition{Threshold, Sustained, Errors, Stalled} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*c = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown condition \"%s\"", ErrInvalidRule, text)
+}
+
+// Match selects the backlogs that a rule applies to. Empty values match
+// everything. Names are matched without regard to case.
+type Match struct {
+	Group    string `yaml:"group,omitempty"`
+	From     string `yaml:"from,omitempty"`
+	To       string `yaml:"to,omitempty"`
+	FromSite string `yaml:"fromSite,omitempty"` // Site of the source computer
+	ToSite   string `yaml:"toSite,omitempty"`   // Site of the destination computer
+	Folder   string `yaml:"folder,omitempty"`
+}
+
+func (m *Match) connection(group string, backlog *core.Backlog) bool {
+	return matches(m.Group, group) &&
+		matches(m.From, backlog.From) && matches(m.To, backlog.To) &&
+		matches(m.FromSite, backlog.FromComputer.Site) && matches(m.ToSite, backlog.ToComputer.Site)
+}
+
+func (m *Match) folder(name string) bool {
+	return matches(m.Folder, name)
+}
+
+// Rule is an alerting rule.
+//
+// Backlog conditions are evaluated against the sum of all folders of a
+// connection, unless the rule matches a specific folder or PerFolder is set,
+// in which case each folder is tracked as a separate alert.
+//
+// If PerSitePair is set, backlog conditions are instead evaluated against the
+// sum of all matched connections between each pair of sites, and each pair is
+// tracked as a separate alert. Connections between computers whose site is
+// unknown are not included.
+type Rule struct {
+	Name      string        `yaml:"name"`
+	Match     Match         `yaml:"match,omitempty"`
+	Condition Condition     `yaml:"condition"`
+	Threshold uint          `yaml:"threshold,omitempty"` // Backlog threshold for the Threshold, Sustained and Stalled conditions
+	Duration  time.Duration `yaml:"-"`                   // Duration for the Sustained and Stalled conditions
+	Count     int           `yaml:"count,omitempty"`     // Number of consecutive errors for the Errors condition
+	PerFolder bool          `yaml:"perFolder,omitempty"`
+
+	// PerSitePair causes backlogs to be rolled up by source and destination
+	// site. It cannot be combined with the Errors condition or with per-folder
+	// evaluation.
+	PerSitePair bool `yaml:"perSitePair,omitempty"`
+
+	// Unscheduled causes backlogs to be evaluated even when the connection's
+	// schedule does not allow replication. By default backlog conditions are
+	// suspended outside of a connection's replication window, where a growing
+	// backlog is expected.
+	Unscheduled bool `yaml:"unscheduled,omitempty"`
+}
+
+// plainRule has the fields of Rule without its methods, which prevents
+// infinite recursion when it is encoded.
+type plainRule Rule
+
+// ruleData is the serializable representation of a rule.
+type ruleData struct {
+	plainRule `yaml:",inline"`
+	Duration  string `yaml:"duration,omitempty"`
+}
+
+// MarshalYAML returns a YAML representation of the rule.
+func (r Rule) MarshalYAML() (interface{}, error) {
+	data := ruleData{plainRule: plainRule(r)}
+	if r.Duration != 0 {
+		data.Duration = r.Duration.String()
+	}
+	return data, nil
+}
+
+// UnmarshalYAML decodes a rule from its YAML representation.
+func (r *Rule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data ruleData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*r = Rule(data.plainRule)
+	if data.Duration != "" {
+		d, err := time.ParseDuration(data.Duration)
+		if err != nil {
+			return fmt.Errorf("%w: rule \"%s\": %v", ErrInvalidRule, r.Name, err)
+		}
+		r.Duration = d
+	}
+	return nil
+}
+
+// perFolder reports whether the rule tracks folders individually.
+func (r *Rule) perFolder() bool {
+	return r.Condition != Errors && (r.PerFolder || r.Match.Folder != "")
+}
+
+// Validate returns an error if the rule is missing values required by its
+// condition.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidRule)
+	}
+	switch r.Condition {
+	case Threshold:
+	case Sustained, Stalled:
+		if r.Duration <= 0 {
+			return fmt.Errorf("%w: rule \"%s\" requires a duration", ErrInvalidRule, r.Name)
+		}
+	case Errors:
+		if r.Count <= 0 {
+			return fmt.Errorf("%w: rule \"%s\" requires a count", ErrInvalidRule, r.Name)
+		}
+	default:
+		return fmt.Errorf("%w: rule \"%s\" has an unknown condition", ErrInvalidRule, r.Name)
+	}
+	if r.PerSitePair && (r.Condition == Errors || r.PerFolder || r.Match.Folder != "") {
+		return fmt.Errorf("%w: rule \"%s\" cannot be evaluated per site pair", ErrInvalidRule, r.Name)
+	}
+	return nil
+}
+
+// DefaultRules is a set of rules suitable for most deployments.
+var DefaultRules = []Rule{
+	{Name: "BacklogSustained", Condition: Sustained, Duration: time.Hour},
+	{Name: "BacklogStalled", Condition: Stalled, Duration: 2 * time.Hour},
+	{Name: "QueryErrors", Condition: Errors, Count: 3},
+}
+
+// LoadRules reads a set of rules from a YAML or JSON file. The file must
+// contain a list of rules:
+//
+//   - name: LargeBacklog
+//     condition: threshold
+//     threshold: 10000
+//   - name: SlowBranch
+//     match:
+//       group: Branch Data
+//     condition: sustained
+//     threshold: 100
+//     duration: 30m
+//   - name: WANBacklog
+//     match:
+//       fromSite: Hub
+//       toSite: Branch
+//     condition: sustained
+//     threshold: 5000
+//     duration: 1h
+//     perSitePair: true
+func LoadRules(path string) (rules []Rule, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	if err = validate(rules); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func validate(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		name := strings.ToLower(rules[i].Name)
+		if names[name] {
+			return fmt.Errorf("%w: \"%s\"", ErrDuplicateRule, rules[i].Name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+func matches(pattern, value string) bool {
+	return pattern == "" || strings.EqualFold(pattern, value)
+}
@@ -0,0 +1,155 @@
+package callstat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is the serializable representation of an error. Errors recognized by
+// the registered ErrorCodec, such as those returned by the DFSR Helper
+// protocol, retain their HRESULT and category, which allows them to be
+// reconstructed by Decode.
+type Error struct {
+	Message  string `json:"message" yaml:"message"`
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	HResult  string `json:"hresult,omitempty" yaml:"hresult,omitempty"` // Hexadecimal, such as 0x800706BA
+}
+
+// NewError returns the serializable representation of err. It returns nil if
+// err is nil.
+func NewError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	e := &Error{Message: err.Error()}
+	if codec.Encode != nil {
+		var (
+			hr    uint32
+			coded bool
+		)
+		e.Category, hr, coded = codec.Encode(err)
+		if coded {
+			e.HResult = fmt.Sprintf("0x%08X", hr)
+		}
+	}
+	return e
+}
+
+// Decode returns an error equivalent to the one that e was created from. It
+// returns nil if e is nil.
+//
+// Errors that carried an HRESULT are reconstructed by the registered
+// ErrorCodec, so that they can still be matched against the sentinel errors of
+// their category. Context errors are returned as the context package's own
+// values.
+func (e *Error) Decode() error {
+	if e == nil {
+		return nil
+	}
+	if e.HResult != "" && codec.Decode != nil {
+		hr, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(e.HResult), "0x"), 16, 32)
+		if err == nil {
+			return codec.Decode(uint32(hr), e.Message)
+		}
+	}
+	switch e.Message {
+	case context.DeadlineExceeded.Error():
+		return context.DeadlineExceeded
+	case context.Canceled.Error():
+		return context.Canceled
+	}
+	return errors.New(e.Message)
+}
+
+// ErrorCodec converts errors that carry an HRESULT to and from their
+// serializable representation. It is provided by the package that defines
+// those errors, so that callstat does not depend on it.
+type ErrorCodec struct {
+	// Encode returns the category of err and its HRESULT. Coded is false if
+	// err does not carry an HRESULT. The category is empty if it is unknown.
+	Encode func(err error) (category string, hr uint32, coded bool)
+
+	// Decode returns an error for the given HRESULT and message.
+	Decode func(hr uint32, message string) error
+}
+
+var codec ErrorCodec
+
+// RegisterErrorCodec sets the codec used by NewError and Decode. It is
+// intended to be called from the init function of the package that defines
+// the errors.
+func RegisterErrorCodec(c ErrorCodec) {
+	codec = c
+}
+
+// callData is the serializable representation of a call.
+type callData struct {
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Start       time.Time  `json:"start" yaml:"start"`
+	End         time.Time  `json:"end" yaml:"end"`
+	Duration    string     `json:"duration" yaml:"duration"` // Informational, ignored when decoding
+	Err         *Error     `json:"error,omitempty" yaml:"error,omitempty"`
+	Inner       []callData `json:"inner,omitempty" yaml:"inner,omitempty"`
+}
+
+func newCallData(c *Call) callData {
+	data := callData{
+		Description: c.Description,
+		Start:       c.Start,
+		End:         c.End,
+		Duration:    c.Duration().String(),
+		Err:         NewError(c.Err),
+	}
+	for i := range c.Inner {
+		data.Inner = append(data.Inner, newCallData(&c.Inner[i]))
+	}
+	return data
+}
+
+func (data *callData) call() Call {
+	c := Call{
+		Description: data.Description,
+		Start:       data.Start,
+		End:         data.End,
+		Err:         data.Err.Decode(),
+	}
+	for i := range data.Inner {
+		c.Inner = append(c.Inner, data.Inner[i].call())
+	}
+	return c
+}
+
+// MarshalJSON returns a JSON representation of the call and its inner calls.
+func (c Call) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newCallData(&c))
+}
+
+// UnmarshalJSON decodes a call from its JSON representation.
+func (c *Call) UnmarshalJSON(b []byte) error {
+	var data callData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*c = data.call()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the call and its inner calls.
+func (c Call) MarshalYAML() (interface{}, error) {
+	return newCallData(&c), nil
+}
+
+// UnmarshalYAML decodes a call from its YAML representation.
+func (c *Call) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data callData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*c = data.call()
+	return nil
+}
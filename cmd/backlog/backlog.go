@@ -28,6 +28,7 @@ var (
 	skipFlag           regexSlice
 	minFlag            uint
 	verboseFlag        bool
+	sysvolFlag         bool
 )
 
 const (
@@ -48,6 +49,7 @@ func init() {
 	flag.Var(&skipFlag, "skip", "regex of hostname to skip")
 	flag.UintVar(&minFlag, "min", 0, "minimum backlog to display")
 	flag.BoolVar(&verboseFlag, "v", false, "verbose")
+	flag.BoolVar(&sysvolFlag, "sysvol", false, "query only the SYSVOL group and report the health of SYSVOL replication")
 
 	rand.Seed(time.Now().UnixNano())
 }
@@ -98,7 +100,7 @@ func main() {
 	}
 }
 
-func run(domain string, iteration uint, min uint, client *helper.Client, connections []core.Backlog) {
+func run(domain *core.Domain, iteration uint, min uint, client *helper.Client, connections []core.Backlog) {
 	var (
 		ctx    context.Context
 		cancel context.CancelFunc
@@ -143,39 +145,97 @@ func run(domain string, iteration uint, min uint, client *helper.Client, connect
 			fmt.Printf("%-15s ", fmt.Sprint(c.Sum()))
 		}
 		fmt.Printf("%v\n", c.Call.Duration())
+		if c.Err != nil {
+			// Failures are often caused by computers that are no longer in use
+			for _, note := range accountNotes(c.From, &c.FromComputer, start) {
+				fmt.Printf("  %s\n", note)
+			}
+			for _, note := range accountNotes(c.To, &c.ToComputer, start) {
+				fmt.Printf("  %s\n", note)
+			}
+		}
 		if verboseFlag {
 			fmt.Printf("Call: %v\n", c.Call)
 		}
 	}
 
 	fmt.Printf("Total Time: %v\n", finish.Sub(start))
+
+	if sysvolFlag {
+		backlogs := make([]*core.Backlog, len(connections))
+		for i := range connections {
+			backlogs[i] = &connections[i]
+		}
+		sysvol(domain, backlogs)
+	}
+}
+
+// sysvol prints the health of SYSVOL replication. Any backlog is reported,
+// since it means that domain controllers may apply different Group Policy.
+func sysvol(domain *core.Domain, backlogs []*core.Backlog) {
+	health := domain.SysvolHealth(backlogs)
+	fmt.Printf("SYSVOL Migration State: %v\n", health.State)
+	if health.State.DFSR() && health.Group == "" {
+		fmt.Printf("SYSVOL is not replicated by any group\n")
+	}
+	for _, dc := range health.Migrating {
+		fmt.Printf("Migration incomplete:    %s\n", dc)
+	}
+	for _, dc := range health.NotMembers {
+		fmt.Printf("Not a member:            %s\n", dc)
+	}
+	for _, member := range health.NotDomainControllers {
+		fmt.Printf("Not a domain controller: %s\n", member)
+	}
+	for _, member := range health.NoInbound {
+		fmt.Printf("No inbound connections:  %s\n", member)
+	}
+	for _, member := range health.Disabled {
+		fmt.Printf("Subscription disabled:   %s\n", member)
+	}
+	for _, member := range health.WritableRODCs {
+		fmt.Printf("Writable RODC:           %s\n", member)
+	}
+	for _, b := range health.Backlogs {
+		if b.Err != nil {
+			fmt.Printf("Query failed:            %s -> %s: %v\n", b.From, b.To, b.Err)
+		} else {
+			fmt.Printf("Backlogged:              %s -> %s: %d\n", b.From, b.To, b.Sum())
+		}
+	}
+	if health.OK() {
+		fmt.Printf("SYSVOL is healthy\n")
+	}
 }
 
-func setup(domain string, groupRegex, fromRegex, toRegex, memberRegex, skipRegex regexSlice) (dom string, connections []core.Backlog, err error) {
+func setup(domain string, groupRegex, fromRegex, toRegex, memberRegex, skipRegex regexSlice) (d *core.Domain, connections []core.Backlog, err error) {
 	client, err := adsi.NewClient()
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
 	defer client.Close()
 
 	if domain == "" {
 		domain, err = dnc(client)
 		if err != nil {
-			return "", nil, err
+			return nil, nil, err
 		}
 	}
-	dom = domain
 
-	d, err := config.Domain(client, domain)
+	data, err := config.Domain(context.Background(), client, domain)
 	if err != nil {
-		return domain, nil, err
+		return nil, nil, err
 	}
+	d = &data
 
 	for g := 0; g < len(d.Groups); g++ {
 		group := &d.Groups[g]
 		if !isMatch(group.Name, groupRegex, true) {
 			continue
 		}
+		if sysvolFlag && !group.Sysvol() {
+			continue
+		}
 
 		for m := 0; m < len(group.Members); m++ {
 			member := &group.Members[m]
@@ -210,9 +270,11 @@ func setup(domain string, groupRegex, fromRegex, toRegex, memberRegex, skipRegex
 				}
 
 				connections = append(connections, core.Backlog{
-					Group: group,
-					From:  from,
-					To:    to,
+					Group:        group,
+					From:         from,
+					To:           to,
+					FromComputer: conn.Computer,
+					ToComputer:   member.Computer,
 				})
 			}
 		}
@@ -1,6 +1,16 @@
 package main
 
-import "gopkg.in/adsi.v0"
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// inactiveAge is the time since the last logon of a computer after which it is
+// considered to be inactive.
+const inactiveAge = 30 * 24 * time.Hour
 
 func dnc(client *adsi.Client) (dnc string, err error) {
 	rootDSE, err := client.Open("LDAP://RootDSE")
@@ -24,3 +34,15 @@ func isMatch(s string, rs regexSlice, emptyValue bool) bool {
 	}
 	return false
 }
+
+// accountNotes describes the problems with the account of a computer that
+// might explain a failed backlog query.
+func accountNotes(host string, computer *core.Computer, now time.Time) (notes []string) {
+	if computer.Disabled {
+		notes = append(notes, fmt.Sprintf("The computer account of %s is disabled", host))
+	}
+	if computer.Inactive(now.Add(-inactiveAge)) {
+		notes = append(notes, fmt.Sprintf("%s last logged on %s", host, computer.LastLogon.Format("2006-01-02")))
+	}
+	return
+}
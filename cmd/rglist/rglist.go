@@ -1,63 +1,484 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
-	"gopkg.in/adsi.v0"
 	"gopkg.in/dfsr.v0/config"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
+	"gopkg.in/dfsr.v0/config/directory/ldapdir"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/lint"
+	"gopkg.in/dfsr.v0/topology"
 )
 
+// inactiveAge is the time since the last logon of a computer after which it is
+// considered to be inactive.
+const inactiveAge = 30 * 24 * time.Hour
+
+var (
+	analyzeFlag  bool
+	resolveFlag  string
+	sysvolFlag   bool
+	suppressFlag string
+	failFlag     string
+	ldapFlag     string
+	userFlag     string
+)
+
+func init() {
+	flag.BoolVar(&analyzeFlag, "analyze", false, "analyze replication group topology for problems")
+	flag.StringVar(&resolveFlag, "resolve", "", "find the replicated folder that serves a DFS namespace path, such as \\\\example.com\\Public\\file.txt")
+	flag.BoolVar(&sysvolFlag, "sysvol", false, "report the health of SYSVOL replication and its migration to DFSR")
+	flag.StringVar(&suppressFlag, "suppress", "", "path of a YAML file listing lint findings to suppress")
+	flag.StringVar(&failFlag, "fail", "warning", "minimum severity of lint findings that cause a non-zero exit status")
+	flag.StringVar(&ldapFlag, "ldap", "", "URL of an LDAP server to query instead of using ADSI, such as ldap://dc1.example.com")
+	flag.StringVar(&userFlag, "user", "", "bind DN or user principal name for LDAP (password is read from LDAP_PASSWORD)")
+}
+
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [lint] [domain]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
+	os.Exit(run(flag.Args()))
+}
 
-	client, err := adsi.NewClient()
+// run lists or lints the DFSR configuration of the domain named by args and
+// returns the exit status of the program. It returns instead of exiting so
+// that the directory is closed on every path.
+func run(args []string) int {
+	lintMode := len(args) > 0 && args[0] == "lint"
+	if lintMode {
+		args = args[1:]
+	}
+
+	var (
+		fail         lint.Severity
+		suppressions []lint.Suppression
+	)
+	if lintMode {
+		if err := fail.UnmarshalText([]byte(failFlag)); err != nil {
+			log.Print(err)
+			return 1
+		}
+		if suppressFlag != "" {
+			var err error
+			if suppressions, err = lint.LoadSuppressions(suppressFlag); err != nil {
+				log.Print(err)
+				return 1
+			}
+		}
+	}
+
+	dir, err := openDirectory()
 	if err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		return 1
 	}
+	defer dir.Close()
 
-	var domain = flag.Arg(0)
+	var domain string
+	if len(args) > 0 {
+		domain = args[0]
+	}
 
 	if domain == "" {
-		dnc, dncErr := rootDNC(client)
+		dnc, dncErr := rootDNC(dir)
 		if dncErr != nil {
-			log.Fatal(dncErr)
+			log.Print(dncErr)
+			return 1
 		}
 		domain = dnc
 	}
 
-	d, err := config.Domain(client, domain)
+	d, err := config.DomainWithDirectory(context.Background(), dir, domain)
 	if err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		return 1
+	}
+
+	if lintMode {
+		if !lintDomain(&d, suppressions, fail) {
+			return 1
+		}
+		return 0
+	}
+
+	if analyzeFlag {
+		analyze(&d)
+		return 0
+	}
+
+	if resolveFlag != "" {
+		resolve(&d, resolveFlag)
+		return 0
+	}
+
+	if sysvolFlag {
+		sysvol(&d)
+		return 0
 	}
 
 	fmt.Printf("      Domain: %-51s ID: %v DN: %-30s Duration: %v\n", d.Description, d.ID, d.DN, d.ConfigDuration)
 	for i := 0; i < len(d.Groups); i++ {
 		group := &d.Groups[i]
-		fmt.Printf("[%3d]   Group: %-50s ID: %v Duration: %v\n", i, group.Name, group.ID, group.ConfigDuration)
+		fmt.Printf("[%3d]   Group: %-50s ID: %v Type: %v Duration: %v\n", i, group.Name, group.ID, group.Type, group.ConfigDuration)
+		if group.Err != nil {
+			fmt.Printf("          Error: %v\n", group.Err)
+			continue
+		}
+		if group.Description != "" {
+			fmt.Printf("          Description: %s\n", group.Description)
+		}
+		if group.Schedule != nil {
+			fmt.Printf("          Schedule: %s\n", describeSchedule(group.Schedule))
+		}
 		for f := 0; f < len(group.Folders); f++ {
 			folder := &group.Folders[f]
 			fmt.Printf("          Folder: %-47s ID: %v\n", folder.Name, folder.ID)
+			for _, path := range namespacePaths(&d, group, folder) {
+				fmt.Printf("            Namespace Path: %s\n", path)
+			}
+			if folder.FileFilter != "" {
+				fmt.Printf("            File Filter: %s\n", folder.FileFilter)
+			}
+			if folder.DirectoryFilter != "" {
+				fmt.Printf("            Directory Filter: %s\n", folder.DirectoryFilter)
+			}
 		}
 		for m := 0; m < len(group.Members); m++ {
 			member := &group.Members[m]
-			fmt.Printf("          Member: %-47s ID: %v Computer: %s\n", member.Name, member.ID, member.Computer.Host)
+			fmt.Printf("          Member: %-47s ID: %v Computer: %s%s%s\n", member.Name, member.ID, member.Computer.Host, describeSite(member.Computer.Site), describeAccount(&member.Computer))
+			if details := describeComputer(&member.Computer); details != "" {
+				fmt.Printf("            Computer: %s\n", details)
+			}
+			for s := 0; s < len(member.Subscriptions); s++ {
+				sub := &member.Subscriptions[s]
+				folder := sub.Folder.Name
+				if folder == "" && sub.Folder.ID != nil {
+					folder = sub.Folder.ID.String()
+				}
+				fmt.Printf("            Subscription[%s]: %-37s Path: %s\n", describeSubscription(sub), folder, sub.RootPath)
+				fmt.Printf("              Staging: %s (%d MB) Conflict and Deleted: %s (%d MB)\n", sub.StagingPath, sub.StagingQuota, sub.ConflictPath, sub.ConflictQuota)
+			}
 			for c := 0; c < len(member.Connections); c++ {
 				conn := &member.Connections[c]
 				enabledMark := " "
 				if conn.Enabled {
 					enabledMark = "x"
 				}
-				fmt.Printf("            Connection[%s]: %-39s ID: %v Computer: %s\n", enabledMark, conn.Name, conn.ID, conn.Computer.Host)
+				crossSite := ""
+				if member.CrossSite(conn) {
+					crossSite = describeSiteLink(&d, conn.Computer.Site, member.Computer.Site)
+				}
+				fmt.Printf("            Connection[%s]: %-39s ID: %v Computer: %s%s%s%s RDC: %s\n", enabledMark, conn.Name, conn.ID, conn.Computer.Host, describeSite(conn.Computer.Site), describeAccount(&conn.Computer), crossSite, describeRDC(conn))
+				if conn.Keywords != "" {
+					fmt.Printf("              Keywords: %s\n", conn.Keywords)
+				}
+				if conn.Schedule != nil {
+					fmt.Printf("              Schedule: %s\n", describeSchedule(conn.Schedule))
+				}
 			}
 		}
 	}
+	if d.NamespacesErr != nil {
+		fmt.Printf("      Namespaces: Error: %v\n", d.NamespacesErr)
+	}
+	for i := 0; i < len(d.Namespaces); i++ {
+		ns := &d.Namespaces[i]
+		fmt.Printf("[%3d]    Namespace: %-45s ID: %v\n", i, ns.Path, ns.ID)
+		for l := 0; l < len(ns.Links); l++ {
+			link := &ns.Links[l]
+			fmt.Printf("          Link: %-49s Targets: %s\n", link.Path, describeTargets(link.Targets))
+		}
+	}
+	if d.SitesErr != nil {
+		fmt.Printf("      Sites: Error: %v\n", d.SitesErr)
+	}
+	for i := 0; i < len(d.Sites); i++ {
+		site := &d.Sites[i]
+		fmt.Printf("[%3d]    Site: %-50s ID: %v Subnets: %s\n", i, site.Name, site.ID, strings.Join(site.Subnets, ", "))
+	}
+	for i := 0; i < len(d.SiteLinks); i++ {
+		link := &d.SiteLinks[i]
+		fmt.Printf("[%3d]    Link: %-50s Cost: %d Interval: %v Sites: %s\n", i, link.Name, link.Cost, link.Interval, strings.Join(link.Sites, ", "))
+	}
 	fmt.Printf("Duration: %v\n", d.ConfigDuration)
+	return 0
+}
+
+// describeSubscription returns a two character mark describing the state of
+// a subscription: "x" if it is enabled and "r" if it is read-only.
+func describeSubscription(s *core.Subscription) string {
+	mark := []byte("  ")
+	if s.Enabled {
+		mark[0] = 'x'
+	}
+	if s.ReadOnly {
+		mark[1] = 'r'
+	}
+	return string(mark)
+}
+
+// describeRDC returns a short description of the remote differential
+// compression settings of a connection.
+func describeRDC(conn *core.Connection) string {
+	switch {
+	case !conn.RDC:
+		return "disabled"
+	case !conn.CrossFileRDC:
+		return "enabled, no cross-file"
+	default:
+		return "enabled"
+	}
+}
+
+// describeComputer returns the operating system, last logon time and role of a
+// computer.
+func describeComputer(c *core.Computer) string {
+	var details []string
+	if c.OperatingSystem != "" {
+		details = append(details, strings.TrimSpace(c.OperatingSystem+" "+c.OperatingSystemVersion))
+	}
+	if !c.LastLogon.IsZero() {
+		details = append(details, "Last Logon: "+c.LastLogon.Local().Format("2006-01-02"))
+	}
+	if c.DomainController {
+		details = append(details, "Domain Controller")
+	}
+	return strings.Join(details, ", ")
+}
+
+// describeAccount returns a suffix marking computers whose accounts are
+// disabled or have not logged on recently, which are often computers that
+// have been decommissioned.
+func describeAccount(c *core.Computer) string {
+	switch {
+	case c.Disabled:
+		return " (disabled)"
+	case c.Inactive(time.Now().Add(-inactiveAge)):
+		return " (inactive)"
+	default:
+		return ""
+	}
+}
+
+// describeSite returns a suffix naming the site of a computer, if it is known.
+func describeSite(site string) string {
+	if site == "" {
+		return ""
+	}
+	return fmt.Sprintf(" Site: %s", site)
+}
+
+// describeSiteLink returns a suffix describing a connection that crosses from
+// one site to another and the cost of the link between them.
+func describeSiteLink(d *core.Domain, from, to string) string {
+	link := d.SiteLink(from, to)
+	if link == nil {
+		return " (cross-site, no site link)"
+	}
+	return fmt.Sprintf(" (cross-site via %s, cost %d)", link.Name, link.Cost)
+}
+
+func resolve(d *core.Domain, path string) {
+	r := d.Resolve(path)
+	if r == nil {
+		fmt.Printf("%s is not within a domain-based namespace\n", path)
+		return
+	}
+	fmt.Printf("Namespace: %s\n", r.Namespace.Path)
+	if r.Link == nil {
+		fmt.Printf("Not within a namespace folder with targets\n")
+		return
+	}
+	fmt.Printf("Link:      %s\n", r.Link.Path)
+	fmt.Printf("Targets:   %s\n", describeTargets(r.Link.Targets))
+	if r.Remainder != "" {
+		fmt.Printf("Path:      %s\n", r.Remainder)
+	}
+	if r.Folder == nil {
+		fmt.Printf("Not replicated by DFSR\n")
+		return
+	}
+	fmt.Printf("Group:     %s\n", r.Group.Name)
+	fmt.Printf("Folder:    %s\n", r.Folder.Name)
+	if r.Remainder != "" && r.Folder.Excluded(r.Remainder) {
+		fmt.Printf("Excluded:  the path is excluded by the folder's filters\n")
+	}
+	for _, member := range r.Members {
+		fmt.Printf("Member:    %s (%s)\n", member.Name, member.Computer.Host)
+	}
+}
+
+// namespacePaths returns the namespace paths of the links that are served by
+// the given replicated folder.
+func namespacePaths(d *core.Domain, group *core.Group, folder *core.Folder) (paths []string) {
+	if folder.DfsPath != "" {
+		paths = append(paths, folder.DfsPath)
+	}
+	for n := range d.Namespaces {
+		for l := range d.Namespaces[n].Links {
+			link := &d.Namespaces[n].Links[l]
+			if strings.EqualFold(link.Path, folder.DfsPath) {
+				continue
+			}
+			if g, f, _ := d.Replicas(link); g == group && f == folder {
+				paths = append(paths, link.Path)
+			}
+		}
+	}
+	return
+}
+
+// describeTargets returns a list of targets, marking those that are offline.
+func describeTargets(targets []core.Target) string {
+	descriptions := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if t.Online {
+			descriptions = append(descriptions, t.Path)
+		} else {
+			descriptions = append(descriptions, t.Path+" (offline)")
+		}
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// lintDomain prints the lint findings for the domain that are not suppressed.
+// It returns false if any of them are at least as severe as fail.
+func lintDomain(d *core.Domain, suppressions []lint.Suppression, fail lint.Severity) (ok bool) {
+	findings, suppressed := lint.Filter(lint.Domain(d), suppressions)
+	ok = true
+	for _, finding := range findings {
+		fmt.Printf("%-7s %s %s: %s\n", strings.ToUpper(finding.Severity.String()), finding.Code, finding.Path(), finding.Message)
+		if finding.Severity >= fail {
+			ok = false
+		}
+	}
+	fmt.Printf("Groups: %d, findings: %d, suppressed: %d\n", len(d.Groups), len(findings), suppressed)
+	return
+}
+
+func analyze(d *core.Domain) {
+	problems := 0
+	for _, analysis := range topology.AnalyzeDomain(d) {
+		if analysis.OK() {
+			continue
+		}
+		problems++
+		fmt.Printf("Group: %s\n", analysis.Group)
+		for _, member := range analysis.NoInbound {
+			fmt.Printf("  No inbound connections:  %s\n", member)
+		}
+		for _, member := range analysis.NoOutbound {
+			fmt.Printf("  No outbound connections: %s\n", member)
+		}
+		for _, link := range analysis.OneWay {
+			fmt.Printf("  One-way connection:      %s -> %s\n", link.From, link.To)
+		}
+		for i, island := range analysis.Islands {
+			fmt.Printf("  Island %d:                %s\n", i+1, strings.Join(island, ", "))
+		}
+		for _, member := range analysis.ArticulationPoints {
+			fmt.Printf("  Single point of failure: %s\n", member)
+		}
+	}
+	fmt.Printf("Groups: %d, with problems: %d\n", len(d.Groups), problems)
+}
+
+func sysvol(d *core.Domain) {
+	if d.SysvolErr != nil {
+		fmt.Printf("Error:           %v\n", d.SysvolErr)
+	}
+	health := d.SysvolHealth(nil)
+	fmt.Printf("Migration state: %v\n", health.State)
+	if health.Group == "" {
+		fmt.Printf("Group:           none\n")
+	} else {
+		fmt.Printf("Group:           %s\n", health.Group)
+	}
+	if d.Sysvol != nil {
+		for i := range d.Sysvol.DomainControllers {
+			dc := &d.Sysvol.DomainControllers[i]
+			kind := "DC"
+			if dc.ReadOnly {
+				kind = "RODC"
+			}
+			member := dc.Member
+			if member == "" {
+				member = "(not a member)"
+			}
+			fmt.Printf("  %-4s %-50s State: %-10v Member: %s%s\n", kind, dc.Computer.Host, dc.State, member, describeSite(dc.Computer.Site))
+		}
+	}
+	if health.State.DFSR() && health.Group == "" {
+		fmt.Printf("  No SYSVOL replication group although DFSR replicates SYSVOL\n")
+	}
+	for _, dc := range health.Migrating {
+		fmt.Printf("  Migration incomplete:    %s\n", dc)
+	}
+	for _, dc := range health.NotMembers {
+		fmt.Printf("  Not a member:            %s\n", dc)
+	}
+	for _, member := range health.NotDomainControllers {
+		fmt.Printf("  Not a domain controller: %s\n", member)
+	}
+	for _, member := range health.NoInbound {
+		fmt.Printf("  No inbound connections:  %s\n", member)
+	}
+	for _, member := range health.Disabled {
+		fmt.Printf("  Subscription disabled:   %s\n", member)
+	}
+	for _, member := range health.WritableRODCs {
+		fmt.Printf("  Writable RODC:           %s\n", member)
+	}
+	if health.OK() {
+		fmt.Printf("No problems found\n")
+	}
+}
+
+// describeSchedule returns a short description of a replication schedule and
+// its state at the current time.
+func describeSchedule(s *core.Schedule) string {
+	zone := "UTC"
+	if s.Local {
+		zone = "local time"
+	}
+	now := time.Now()
+	switch {
+	case s.Always():
+		return fmt.Sprintf("always, %s", zone)
+	case s.Never():
+		return fmt.Sprintf("never, %s", zone)
+	case s.Allowed(now):
+		return fmt.Sprintf("restricted, %s, replicating now at %s", zone, s.Bandwidth(now))
+	}
+	if next, ok := s.NextAllowed(now); ok {
+		return fmt.Sprintf("restricted, %s, next window at %s", zone, next.Format("Mon 15:04"))
+	}
+	return fmt.Sprintf("restricted, %s", zone)
+}
+
+func openDirectory() (directory.Directory, error) {
+	if ldapFlag == "" {
+		return adsidir.Open()
+	}
+	return ldapdir.Dial(ldapFlag, ldapdir.Config{
+		BindDN:   userFlag,
+		Password: os.Getenv("LDAP_PASSWORD"),
+	})
 }
 
-func rootDNC(client *adsi.Client) (string, error) {
-	rootDSE, err := client.Open("LDAP://RootDSE")
+func rootDNC(dir directory.Directory) (string, error) {
+	rootDSE, err := dir.RootDSE()
 	if err != nil {
 		return "", err
 	}
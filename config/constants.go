@@ -4,6 +4,10 @@ import "errors"
 
 const updateChanSize = 16
 
+// crossRefDomain is set in the systemFlags attribute of crossRef objects
+// that refer to the naming context of a domain.
+const crossRefDomain = 0x2
+
 var (
 	// ErrClosed is returned from calls to a service or interface in the event
 	// that the Close() function has already been called.
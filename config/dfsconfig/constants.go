@@ -0,0 +1,9 @@
+package dfsconfig
+
+import "errors"
+
+var (
+	// ErrInvalidTargetList is returned when the target list of a namespace or
+	// link cannot be decoded.
+	ErrInvalidTargetList = errors.New("The DFS namespace target list is invalid.")
+)
@@ -0,0 +1,185 @@
+// Package dfsconfig retrieves domain-based DFS namespace configuration from
+// the Dfs-Configuration container of an Active Directory domain.
+//
+// Only namespaces in Windows Server 2008 mode, which are stored as
+// msDFS-Namespacev2 and msDFS-Linkv2 objects, are supported.
+package dfsconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// DfsConfiguration provides a means of querying DFS namespace configuration.
+type DfsConfiguration struct {
+	dir      directory.Directory
+	domainDN string
+}
+
+// New returns a new DFS namespace configuration manager for the given domain.
+//
+// The provided ADSI client is retained by the manager. It is the caller's
+// responsibility to close the client at an appropriate time.
+func New(client *adsi.Client, domain string) *DfsConfiguration {
+	return NewWithDirectory(adsidir.New(client), domain)
+}
+
+// NewWithDirectory returns a new DFS namespace configuration manager for the
+// given domain that performs its queries against the provided directory.
+//
+// The directory is retained by the manager. It is the caller's responsibility
+// to close the directory at an appropriate time.
+func NewWithDirectory(dir directory.Directory, domain string) *DfsConfiguration {
+	return &DfsConfiguration{
+		dir:      dir,
+		domainDN: domainDN(domain),
+	}
+}
+
+// Namespaces retrieves the domain-based namespaces of the domain and their
+// links. If the domain has no Dfs-Configuration container no namespaces are
+// returned.
+func (dc *DfsConfiguration) Namespaces(ctx context.Context) (namespaces []core.Namespace, err error) {
+	container, err := dc.dir.Open(combineDN("CN=Dfs-Configuration,CN=System", dc.domainDN))
+	if errors.Is(err, directory.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer container.Close()
+
+	iter, err := container.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for {
+		n, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer n.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		class, err := n.Class()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(class, "msDFS-Namespacev2") {
+			continue
+		}
+
+		namespace, err := dc.namespace(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	return
+}
+
+func (dc *DfsConfiguration) namespace(ctx context.Context, n directory.Object) (namespace core.Namespace, err error) {
+	namespace.Name, err = n.Name()
+	if err != nil {
+		return
+	}
+	namespace.Name = strings.TrimPrefix(namespace.Name, "CN=")
+
+	namespace.ID, err = n.GUID()
+	if err != nil {
+		return
+	}
+
+	namespace.Path = `\\` + domainName(dc.domainDN) + `\` + namespace.Name
+
+	namespace.Comment, err = n.AttrString("msDFS-Commentv2")
+	if err != nil {
+		return
+	}
+
+	namespace.Targets, err = targets(n)
+	if err != nil {
+		return
+	}
+
+	namespace.Links, err = dc.links(ctx, n, namespace.Path)
+	return
+}
+
+func (dc *DfsConfiguration) links(ctx context.Context, n directory.Object, root string) (links []core.Link, err error) {
+	iter, err := n.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for {
+		l, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer l.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		class, err := l.Class()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(class, "msDFS-Linkv2") {
+			continue
+		}
+
+		link, err := dc.link(l, root)
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, link)
+	}
+
+	return
+}
+
+func (dc *DfsConfiguration) link(l directory.Object, root string) (link core.Link, err error) {
+	link.ID, err = l.GUID()
+	if err != nil {
+		return
+	}
+
+	path, err := l.AttrString("msDFS-LinkPathv2")
+	if err != nil {
+		return
+	}
+	link.Name = strings.Trim(strings.Replace(path, "/", `\`, -1), `\`)
+	link.Path = root + `\` + link.Name
+
+	link.Comment, err = l.AttrString("msDFS-Commentv2")
+	if err != nil {
+		return
+	}
+
+	link.Targets, err = targets(l)
+	return
+}
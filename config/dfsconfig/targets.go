@@ -0,0 +1,71 @@
+package dfsconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// targetStateOnline is the value of the state attribute of a target that is
+// referred to clients.
+const targetStateOnline = 2
+
+// targetList is the XML document stored in the msDFS-TargetListv2 attribute.
+type targetList struct {
+	Targets []struct {
+		State int    `xml:"state,attr"`
+		Path  string `xml:",chardata"`
+	} `xml:"target"`
+}
+
+// targets returns the targets stored in the msDFS-TargetListv2 attribute of
+// o.
+func targets(o directory.Object) (targets []core.Target, err error) {
+	data, err := o.AttrBytes("msDFS-TargetListv2")
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+
+	var list targetList
+	decoder := xml.NewDecoder(bytes.NewReader(decodeText(data)))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil // Already decoded
+	}
+	if err = decoder.Decode(&list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTargetList, err)
+	}
+
+	for _, t := range list.Targets {
+		targets = append(targets, core.Target{
+			Path:   t.Path,
+			Online: t.State == targetStateOnline,
+		})
+	}
+	return
+}
+
+// decodeText converts text that is encoded in UTF-16 with a byte order mark
+// to UTF-8. Other text is returned as is.
+func decodeText(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE:
+		order = binary.LittleEndian
+	case len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF:
+		order = binary.BigEndian
+	default:
+		return data
+	}
+
+	units := make([]uint16, (len(data)-2)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2+i*2:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,36 @@
+package dfsconfig
+
+import (
+	"strings"
+
+	"gopkg.in/dfsr.v0/config/directory"
+)
+
+func domainDN(domain string) string {
+	if strings.Index(strings.ToLower(domain), "dc=") == 0 {
+		return domain
+	}
+	components := strings.Split(domain, ".")
+	for i := range components {
+		components[i] = "DC=" + components[i]
+	}
+	return strings.Join(components, ",")
+}
+
+// domainName returns the DNS name of the domain with the given distinguished
+// name.
+func domainName(dn string) string {
+	var labels []string
+	for dn != "" {
+		var rdn string
+		rdn, dn = directory.SplitDN(dn)
+		if len(rdn) > 3 && strings.EqualFold(rdn[:3], "dc=") {
+			labels = append(labels, rdn[3:])
+		}
+	}
+	return strings.ToLower(strings.Join(labels, "."))
+}
+
+func combineDN(components ...string) string {
+	return strings.Join(components, ",")
+}
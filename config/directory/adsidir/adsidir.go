@@ -0,0 +1,288 @@
+// Package adsidir provides a directory.Directory implementation that uses the
+// Active Directory Service Interfaces. It is only functional on Windows.
+package adsidir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/directory"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+var _ = (directory.Directory)((*Directory)(nil))
+
+// hresultNoSuchObject is the HRESULT of ERROR_DS_NO_SUCH_OBJECT.
+const hresultNoSuchObject = 0x80072030
+
+// hresultPropertyNotFound is the HRESULT of E_ADS_PROPERTY_NOT_FOUND.
+const hresultPropertyNotFound = 0x8000500D
+
+// Directory provides access to Active Directory through ADSI.
+type Directory struct {
+	client *adsi.Client
+	owned  bool
+}
+
+// New returns a directory that uses the given ADSI client. The client is not
+// closed when the directory is closed.
+func New(client *adsi.Client) *Directory {
+	return &Directory{client: client}
+}
+
+// Open returns a directory backed by a new ADSI client, which is closed when
+// the directory is closed.
+func Open() (*Directory, error) {
+	client, err := adsi.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Directory{client: client, owned: true}, nil
+}
+
+// Open returns the object with the given distinguished name.
+func (d *Directory) Open(dn string) (directory.Object, error) {
+	o, err := d.client.Open(path(dn))
+	if err != nil {
+		return nil, translate(err, dn)
+	}
+	return &object{dir: d, o: o}, nil
+}
+
+// RootDSE returns the root DSE of the directory server.
+func (d *Directory) RootDSE() (directory.Object, error) {
+	o, err := d.client.Open("LDAP://RootDSE")
+	if err != nil {
+		return nil, err
+	}
+	return &object{dir: d, o: o}, nil
+}
+
+// Close releases the ADSI client if it was created by the directory.
+func (d *Directory) Close() {
+	if d.owned {
+		d.client.Close()
+	}
+}
+
+// object is a directory object backed by an ADSI object.
+type object struct {
+	dir *Directory
+	o   *adsi.Object
+}
+
+func (o *object) Name() (string, error) {
+	return o.o.Name()
+}
+
+func (o *object) Class() (string, error) {
+	return o.o.Class()
+}
+
+func (o *object) GUID() (*ole.GUID, error) {
+	return o.o.GUID()
+}
+
+func (o *object) DN() (string, error) {
+	p, err := o.o.Path()
+	if err != nil {
+		return "", err
+	}
+	return dn(p), nil
+}
+
+func (o *object) Parent() (string, error) {
+	p, err := o.o.Parent()
+	if err != nil {
+		return "", err
+	}
+	return dn(p), nil
+}
+
+func (o *object) AttrString(name string) (string, error) {
+	value, err := o.o.AttrString(name)
+	if missing(err) {
+		return "", nil
+	}
+	return value, err
+}
+
+func (o *object) AttrStrings(name string) ([]string, error) {
+	values, err := o.o.Attr(name)
+	if missing(err) {
+		return nil, nil
+	}
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", directory.ErrInvalidString, name)
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+func (o *object) AttrBool(name string) (bool, error) {
+	value, err := o.o.AttrBool(name)
+	return value, translate(err, name)
+}
+
+func (o *object) AttrInt(name string) (int, error) {
+	value, err := o.o.AttrInt(name)
+	return value, translate(err, name)
+}
+
+func (o *object) AttrInt64(name string) (int64, error) {
+	values, err := o.o.Attr(name)
+	if err != nil {
+		return 0, translate(err, name)
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	switch v := values[0].(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case *ole.IDispatch:
+		// Large integers are provided as IADsLargeInteger objects
+		return largeInteger(v)
+	default:
+		return 0, directory.ErrInvalidInt
+	}
+}
+
+func (o *object) AttrBytes(name string) ([]byte, error) {
+	values, err := o.o.Attr(name)
+	if missing(err) {
+		return nil, nil
+	}
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	b, ok := values[0].([]byte)
+	if !ok {
+		return nil, directory.ErrInvalidBytes
+	}
+	return b, nil
+}
+
+func (o *object) Children() (directory.Iterator, error) {
+	c, err := o.o.ToContainer()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := c.Children()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &iterator{dir: o.dir, c: c, iter: iter}, nil
+}
+
+func (o *object) Child(class, rdn string) (directory.Object, error) {
+	c, err := o.o.ToContainer()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	child, err := c.Object(class, rdn)
+	if err != nil {
+		return nil, translate(err, rdn)
+	}
+	return &object{dir: o.dir, o: child}, nil
+}
+
+func (o *object) Close() {
+	o.o.Close()
+}
+
+// iterator iterates over the children of an ADSI container.
+type iterator struct {
+	dir  *Directory
+	c    *adsi.Container
+	iter *adsi.ObjectIter
+}
+
+func (i *iterator) Next() (directory.Object, error) {
+	o, err := i.iter.Next()
+	if err == io.EOF || (err == nil && o == nil) {
+		return nil, directory.ErrDone
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &object{dir: i.dir, o: o}, nil
+}
+
+func (i *iterator) Close() {
+	i.iter.Close()
+	i.c.Close()
+}
+
+// translate wraps errors reporting that an object or attribute does not exist
+// with directory.ErrNotFound.
+func translate(err error, name string) error {
+	var oleErr *ole.OleError
+	if errors.As(err, &oleErr) {
+		switch uint32(oleErr.Code()) {
+		case hresultNoSuchObject, hresultPropertyNotFound:
+			return fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+		}
+	}
+	return err
+}
+
+// missing reports whether err indicates that an attribute is not present on
+// an object. ADSI reports missing attributes as errors, while the directory
+// package expects an empty value.
+func missing(err error) bool {
+	var oleErr *ole.OleError
+	return errors.As(err, &oleErr) && uint32(oleErr.Code()) == hresultPropertyNotFound
+}
+
+// largeInteger returns the value of an IADsLargeInteger object.
+func largeInteger(disp *ole.IDispatch) (int64, error) {
+	high, err := oleutil.GetProperty(disp, "HighPart")
+	if err != nil {
+		return 0, err
+	}
+	defer high.Clear()
+
+	low, err := oleutil.GetProperty(disp, "LowPart")
+	if err != nil {
+		return 0, err
+	}
+	defer low.Clear()
+
+	h, ok := high.Value().(int32)
+	if !ok {
+		return 0, directory.ErrInvalidInt
+	}
+	l, ok := low.Value().(int32)
+	if !ok {
+		return 0, directory.ErrInvalidInt
+	}
+	return int64(h)<<32 | int64(uint32(l)), nil
+}
+
+func path(dn string) string {
+	return "LDAP://" + dn
+}
+
+func dn(path string) string {
+	return strings.TrimPrefix(path, "LDAP://")
+}
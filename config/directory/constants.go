@@ -0,0 +1,31 @@
+package directory
+
+import "errors"
+
+var (
+	// ErrDone is returned by Iterator.Next when there are no more objects.
+	ErrDone = errors.New("No more objects.")
+
+	// ErrNotFound is returned when an object or attribute does not exist.
+	ErrNotFound = errors.New("The directory object does not exist.")
+
+	// ErrInvalidGUID is returned when an object GUID is not 16 bytes long.
+	ErrInvalidGUID = errors.New("The object GUID is invalid.")
+
+	// ErrInvalidString is returned when an attribute value is not a string.
+	ErrInvalidString = errors.New("The attribute value is not a string.")
+
+	// ErrInvalidBool is returned when an attribute value is not a boolean.
+	ErrInvalidBool = errors.New("The attribute value is not a boolean.")
+
+	// ErrInvalidInt is returned when an attribute value is not an integer.
+	ErrInvalidInt = errors.New("The attribute value is not an integer.")
+
+	// ErrInvalidBytes is returned when an attribute value is not an octet
+	// string.
+	ErrInvalidBytes = errors.New("The attribute value is not an octet string.")
+
+	// ErrClosed is returned from calls to a directory in the event that the
+	// Close() function has already been called.
+	ErrClosed = errors.New("Directory is closing or already closed.")
+)
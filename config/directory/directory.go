@@ -0,0 +1,100 @@
+// Package directory defines the directory service operations that are needed
+// to retrieve DFSR configuration from Active Directory.
+//
+// Implementations are provided by the adsidir package, which uses the Active
+// Directory Service Interfaces on Windows, the ldapdir package, which speaks
+// LDAP directly and works on any platform, and the memdir package, which is
+// an in-process directory that is loaded with fixtures.
+//
+// Objects are identified by plain distinguished names, without the LDAP://
+// prefix used by ADSI paths.
+package directory
+
+import (
+	"context"
+
+	"github.com/go-ole/go-ole"
+)
+
+// Directory provides access to the objects of a directory service.
+type Directory interface {
+	// Open returns the object with the given distinguished name.
+	Open(dn string) (Object, error)
+
+	// RootDSE returns the root DSE of the directory server.
+	RootDSE() (Object, error)
+
+	// Close releases any resources consumed by the directory.
+	Close()
+}
+
+// Object is an object in a directory.
+type Object interface {
+	// Name returns the relative distinguished name of the object, such as
+	// "CN=Topology".
+	Name() (string, error)
+
+	// Class returns the most specific object class of the object.
+	Class() (string, error)
+
+	// GUID returns the object's GUID.
+	GUID() (*ole.GUID, error)
+
+	// DN returns the distinguished name of the object.
+	DN() (string, error)
+
+	// Parent returns the distinguished name of the object's parent.
+	Parent() (string, error)
+
+	// AttrString returns the first value of the given attribute as a string.
+	// It returns an empty string if the object does not have the attribute.
+	AttrString(name string) (string, error)
+
+	// AttrStrings returns all values of the given attribute as strings. It
+	// returns nil if the object does not have the attribute.
+	AttrStrings(name string) ([]string, error)
+
+	// AttrBool returns the first value of the given attribute as a boolean.
+	AttrBool(name string) (bool, error)
+
+	// AttrInt returns the first value of the given attribute as an integer.
+	AttrInt(name string) (int, error)
+
+	// AttrInt64 returns the first value of the given large integer
+	// attribute, such as a timestamp.
+	AttrInt64(name string) (int64, error)
+
+	// AttrBytes returns the first value of the given attribute as a byte
+	// slice. It returns nil if the object does not have the attribute.
+	AttrBytes(name string) ([]byte, error)
+
+	// Children returns an iterator over the immediate children of the object.
+	Children() (Iterator, error)
+
+	// Child returns the immediate child of the object with the given object
+	// class and relative distinguished name.
+	Child(class, rdn string) (Object, error)
+
+	// Close releases any resources consumed by the object.
+	Close()
+}
+
+// Iterator iterates over a set of directory objects.
+type Iterator interface {
+	// Next returns the next object. It returns ErrDone when there are no
+	// more objects.
+	Next() (Object, error)
+
+	// Close releases any resources consumed by the iterator.
+	Close()
+}
+
+// Searcher is implemented by directories that can retrieve every object of a
+// class beneath a base object with a single subtree search.
+type Searcher interface {
+	// Search returns the objects of the given class that are beneath the
+	// base object. Implementations should retrieve at least the requested
+	// attributes in addition to objectClass and objectGUID. The search is
+	// abandoned if ctx is cancelled before it completes.
+	Search(ctx context.Context, base, class string, attributes []string) ([]Object, error)
+}
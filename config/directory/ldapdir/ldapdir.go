@@ -0,0 +1,358 @@
+// Package ldapdir provides a directory.Directory implementation that speaks
+// LDAP directly. Unlike the adsidir package it does not depend on the
+// component object model, which allows DFSR configuration to be retrieved from
+// hosts that are not running Windows.
+//
+//   dir, err := ldapdir.Dial("ldap://dc1.example.com", ldapdir.Config{
+//     BindDN:   "svc-dfsr@example.com",
+//     Password: password,
+//   })
+//   if err != nil {
+//     return err
+//   }
+//   defer dir.Close()
+//   domain, err := config.DomainWithDirectory(ctx, dir, "example.com")
+package ldapdir
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/dfsr.v0/config/directory"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ole/go-ole"
+)
+
+var (
+	_ = (directory.Directory)((*Directory)(nil))
+	_ = (directory.Searcher)((*Directory)(nil))
+)
+
+const defaultPageSize = 500
+
+// Config holds the connection settings for an LDAP directory.
+type Config struct {
+	// BindDN and Password are used for a simple bind. If both are empty and
+	// Kerberos is nil an anonymous connection is used.
+	BindDN   string
+	Password string
+
+	// Kerberos, if not nil, is used to perform a GSSAPI bind instead of a
+	// simple bind. Clients can be created from a keytab or credentials cache
+	// with the github.com/go-ldap/ldap/v3/gssapi package.
+	Kerberos ldap.GSSAPIClient
+
+	// ServicePrincipal is the service principal name of the directory server
+	// used for Kerberos authentication. If empty, ldap/<host> is used.
+	ServicePrincipal string
+
+	// StartTLS upgrades ldap:// connections to TLS before binding.
+	StartTLS bool
+
+	// TLSConfig is used for ldaps:// and StartTLS connections.
+	TLSConfig *tls.Config
+
+	// PageSize is the number of entries requested per page when listing
+	// children. If zero a default page size is used.
+	PageSize uint32
+}
+
+// Directory provides access to an LDAP directory.
+type Directory struct {
+	conn     *ldap.Conn
+	pageSize uint32
+}
+
+// Dial connects and binds to the LDAP server at the given URL, such as
+// ldap://dc1.example.com or ldaps://dc1.example.com:636.
+func Dial(addr string, config Config) (*Directory, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []ldap.DialOpt
+	if config.TLSConfig != nil {
+		opts = append(opts, ldap.DialWithTLSConfig(config.TLSConfig))
+	}
+
+	conn, err := ldap.DialURL(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.StartTLS && strings.EqualFold(u.Scheme, "ldap") {
+		tc := config.TLSConfig
+		if tc == nil {
+			tc = &tls.Config{ServerName: u.Hostname()}
+		}
+		if err = conn.StartTLS(tc); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	switch {
+	case config.Kerberos != nil:
+		spn := config.ServicePrincipal
+		if spn == "" {
+			spn = fmt.Sprintf("ldap/%s", u.Hostname())
+		}
+		err = conn.GSSAPIBind(config.Kerberos, spn, "")
+	case config.BindDN != "" || config.Password != "":
+		err = conn.Bind(config.BindDN, config.Password)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return New(conn, config.PageSize), nil
+}
+
+// DomainURL returns the URL of an LDAP server of the given domain. It replaces
+// the host of addr with the DNS name of the domain and retains its scheme and
+// port, so that ldaps://dc1.example.com:636 becomes
+// ldaps://child.example.com:636 for the child.example.com domain. The domain
+// may be given as a DNS name or as a distinguished name. If domain is empty
+// addr is returned unchanged.
+func DomainURL(addr, domain string) (string, error) {
+	if domain == "" {
+		return addr, nil
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(domain, "=") {
+		domain = domainName(domain)
+	}
+	if port := u.Port(); port != "" {
+		u.Host = domain + ":" + port
+	} else {
+		u.Host = domain
+	}
+	return u.String(), nil
+}
+
+// domainName returns the DNS name of the domain with the given distinguished
+// name.
+func domainName(dn string) string {
+	var labels []string
+	for dn != "" {
+		var rdn string
+		rdn, dn = directory.SplitDN(dn)
+		if len(rdn) > 3 && strings.EqualFold(rdn[:3], "dc=") {
+			labels = append(labels, rdn[3:])
+		}
+	}
+	return strings.ToLower(strings.Join(labels, "."))
+}
+
+// New returns a directory that uses the given LDAP connection, which must
+// already be bound. The connection is closed when the directory is closed.
+//
+// If pageSize is zero a default page size is used.
+func New(conn *ldap.Conn, pageSize uint32) *Directory {
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	return &Directory{
+		conn:     conn,
+		pageSize: pageSize,
+	}
+}
+
+// Open returns the object with the given distinguished name.
+func (d *Directory) Open(dn string) (directory.Object, error) {
+	result, err := d.conn.Search(ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", []string{"*"}, nil))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, fmt.Errorf("%w: %s", directory.ErrNotFound, dn)
+		}
+		return nil, err
+	}
+	if len(result.Entries) == 0 {
+		return nil, fmt.Errorf("%w: %s", directory.ErrNotFound, dn)
+	}
+	return &object{dir: d, entry: result.Entries[0]}, nil
+}
+
+// Search returns the objects of the given class that are beneath the base
+// object. It performs a single paged subtree search, which is abandoned if
+// ctx is cancelled.
+func (d *Directory) Search(ctx context.Context, base, class string, attributes []string) (objects []directory.Object, err error) {
+	attributes = append([]string{"objectClass", "objectGUID"}, attributes...)
+	filter := fmt.Sprintf("(objectClass=%s)", ldap.EscapeFilter(class))
+	paging := ldap.NewControlPaging(d.pageSize)
+
+	for {
+		response := d.conn.SearchAsync(ctx, ldap.NewSearchRequest(
+			base, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			filter, attributes, []ldap.Control{paging}), 0)
+
+		var controls []ldap.Control
+		for response.Next() {
+			if entry := response.Entry(); entry != nil {
+				objects = append(objects, &object{dir: d, entry: entry})
+			}
+			if c := response.Controls(); len(c) > 0 {
+				controls = c
+			}
+		}
+		if err = response.Err(); err != nil {
+			return nil, err
+		}
+		if err = ctx.Err(); err != nil {
+			return nil, err // The response ends without an error when cancelled
+		}
+
+		next, ok := ldap.FindControl(controls, ldap.ControlTypePaging).(*ldap.ControlPaging)
+		if !ok || len(next.Cookie) == 0 {
+			return objects, nil
+		}
+		paging.SetCookie(next.Cookie)
+	}
+}
+
+// RootDSE returns the root DSE of the directory server.
+func (d *Directory) RootDSE() (directory.Object, error) {
+	return d.Open("")
+}
+
+// Close closes the LDAP connection.
+func (d *Directory) Close() {
+	d.conn.Close()
+}
+
+// object is a directory object backed by an LDAP search result entry.
+type object struct {
+	dir   *Directory
+	entry *ldap.Entry
+}
+
+func (o *object) Name() (string, error) {
+	rdn, _ := directory.SplitDN(o.entry.DN)
+	return rdn, nil
+}
+
+func (o *object) Class() (string, error) {
+	classes := o.entry.GetEqualFoldAttributeValues("objectClass")
+	if len(classes) == 0 {
+		return "", fmt.Errorf("%w: objectClass", directory.ErrNotFound)
+	}
+	return classes[len(classes)-1], nil // The most specific class is listed last
+}
+
+func (o *object) GUID() (*ole.GUID, error) {
+	return directory.ParseGUID(o.entry.GetEqualFoldRawAttributeValue("objectGUID"))
+}
+
+func (o *object) DN() (string, error) {
+	return o.entry.DN, nil
+}
+
+func (o *object) Parent() (string, error) {
+	_, parent := directory.SplitDN(o.entry.DN)
+	return parent, nil
+}
+
+func (o *object) AttrString(name string) (string, error) {
+	return o.entry.GetEqualFoldAttributeValue(name), nil
+}
+
+func (o *object) AttrStrings(name string) ([]string, error) {
+	if values := o.entry.GetEqualFoldAttributeValues(name); len(values) > 0 {
+		return values, nil
+	}
+	return nil, nil
+}
+
+func (o *object) AttrBool(name string) (bool, error) {
+	value := o.entry.GetEqualFoldAttributeValue(name)
+	if value == "" {
+		return false, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseBool(value)
+}
+
+func (o *object) AttrInt(name string) (int, error) {
+	value := o.entry.GetEqualFoldAttributeValue(name)
+	if value == "" {
+		return 0, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseInt(value)
+}
+
+func (o *object) AttrInt64(name string) (int64, error) {
+	value := o.entry.GetEqualFoldAttributeValue(name)
+	if value == "" {
+		return 0, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseInt64(value)
+}
+
+func (o *object) AttrBytes(name string) ([]byte, error) {
+	if value := o.entry.GetEqualFoldRawAttributeValue(name); len(value) > 0 {
+		return value, nil
+	}
+	return nil, nil
+}
+
+func (o *object) Children() (directory.Iterator, error) {
+	result, err := o.dir.conn.SearchWithPaging(ldap.NewSearchRequest(
+		o.entry.DN, ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)", []string{"*"}, nil), o.dir.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{dir: o.dir, entries: result.Entries}, nil
+}
+
+func (o *object) Child(class, rdn string) (directory.Object, error) {
+	child, err := o.dir.Open(rdn + "," + o.entry.DN)
+	if err != nil {
+		return nil, err
+	}
+	if !hasClass(child.(*object).entry, class) {
+		return nil, fmt.Errorf("%w: %s is not of class %s", directory.ErrNotFound, rdn, class)
+	}
+	return child, nil
+}
+
+func (o *object) Close() {
+}
+
+// iterator iterates over a set of LDAP search result entries.
+type iterator struct {
+	dir     *Directory
+	entries []*ldap.Entry
+}
+
+func (i *iterator) Next() (directory.Object, error) {
+	if len(i.entries) == 0 {
+		return nil, directory.ErrDone
+	}
+	entry := i.entries[0]
+	i.entries = i.entries[1:]
+	return &object{dir: i.dir, entry: entry}, nil
+}
+
+func (i *iterator) Close() {
+	i.entries = nil
+}
+
+func hasClass(entry *ldap.Entry, class string) bool {
+	for _, c := range entry.GetEqualFoldAttributeValues("objectClass") {
+		if strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
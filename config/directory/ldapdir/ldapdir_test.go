@@ -0,0 +1,27 @@
+package ldapdir
+
+import "testing"
+
+func TestDomainURL(t *testing.T) {
+	tests := []struct {
+		Addr   string
+		Domain string
+		Want   string
+	}{
+		{"ldap://dc1.example.com", "", "ldap://dc1.example.com"},
+		{"ldap://dc1.example.com", "child.example.com", "ldap://child.example.com"},
+		{"ldaps://dc1.example.com:636", "child.example.com", "ldaps://child.example.com:636"},
+		{"ldap://dc1.example.com", "DC=Child,DC=example,DC=com", "ldap://child.example.com"},
+		{"ldap://10.0.0.1:389", "dc=example,dc=com", "ldap://example.com:389"},
+	}
+	for _, tt := range tests {
+		got, err := DomainURL(tt.Addr, tt.Domain)
+		if err != nil {
+			t.Errorf("DomainURL(%q, %q): %v", tt.Addr, tt.Domain, err)
+			continue
+		}
+		if got != tt.Want {
+			t.Errorf("DomainURL(%q, %q) = %q, want %q", tt.Addr, tt.Domain, got, tt.Want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package memdir
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadLDIF adds the entries described by the LDIF content in r to the
+// directory.
+//
+// Only the subset of LDIF that is needed for fixtures is supported: entries
+// are separated by blank lines, lines starting with a space continue the
+// previous line, lines starting with # are comments and values following a
+// double colon are base64 encoded. Change records are not supported.
+func (d *Directory) LoadLDIF(r io.Reader) error {
+	entries, err := parseLDIF(r)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		d.Add(entry)
+	}
+	return nil
+}
+
+func parseLDIF(r io.Reader) (entries []Entry, err error) {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, " ") && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		case strings.HasPrefix(line, "#"):
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var current *Entry
+	flush := func() {
+		if current != nil {
+			entries = append(entries, *current)
+			current = nil
+		}
+	}
+
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("ldif: line %d: missing separator", n+1)
+		}
+		attr, value := line[:i], line[i+1:]
+		if strings.HasPrefix(value, ":") {
+			decoded, derr := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if derr != nil {
+				return nil, fmt.Errorf("ldif: line %d: %v", n+1, derr)
+			}
+			value = string(decoded)
+		} else {
+			value = strings.TrimSpace(value)
+		}
+
+		if strings.EqualFold(attr, "dn") {
+			flush()
+			current = &Entry{DN: value, Attributes: make(map[string][]string)}
+			continue
+		}
+		if strings.EqualFold(attr, "version") && current == nil {
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("ldif: line %d: attribute before dn", n+1)
+		}
+		current.Attributes[attr] = append(current.Attributes[attr], value)
+	}
+	flush()
+
+	return
+}
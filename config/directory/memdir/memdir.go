@@ -0,0 +1,305 @@
+// Package memdir provides an in-process directory.Directory implementation
+// that holds its objects in memory. It stands in for Active Directory in
+// tests and labs and is typically loaded with LDIF fixtures that describe the
+// DFSR-GlobalSettings container of a domain:
+//
+//   dir := memdir.New()
+//   if err := dir.LoadLDIF(file); err != nil {
+//     return err
+//   }
+//   domain, err := config.DomainWithDirectory(ctx, dir, "example.com")
+package memdir
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/dfsr.v0/config/directory"
+
+	"github.com/go-ole/go-ole"
+)
+
+var (
+	_ = (directory.Directory)((*Directory)(nil))
+	_ = (directory.Searcher)((*Directory)(nil))
+)
+
+// Entry is an object stored in the directory. Attribute names are matched
+// without regard to case.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// values returns the values of the given attribute.
+func (e *Entry) values(name string) []string {
+	if values, ok := e.Attributes[name]; ok {
+		return values
+	}
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func (e *Entry) value(name string) string {
+	if values := e.values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Directory is an in-memory directory. It is safe for concurrent use.
+type Directory struct {
+	mutex   sync.RWMutex
+	entries map[string]*Entry // Maps lower-cased distinguished names to entries
+	rootDSE *Entry
+}
+
+// New returns a new empty directory.
+func New() *Directory {
+	return &Directory{
+		entries: make(map[string]*Entry),
+		rootDSE: &Entry{Attributes: make(map[string][]string)},
+	}
+}
+
+// Add adds an entry to the directory, replacing any existing entry with the
+// same distinguished name. An entry with an empty distinguished name replaces
+// the root DSE.
+//
+// The objectGUID attribute may hold either the 16 byte binary representation
+// of a GUID or its string form.
+func (d *Directory) Add(entry Entry) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if entry.Attributes == nil {
+		entry.Attributes = make(map[string][]string)
+	}
+	if entry.DN == "" {
+		d.rootDSE = &entry
+		return
+	}
+	d.entries[key(entry.DN)] = &entry
+}
+
+// Remove removes the entry with the given distinguished name.
+func (d *Directory) Remove(dn string) {
+	d.mutex.Lock()
+	delete(d.entries, key(dn))
+	d.mutex.Unlock()
+}
+
+// Open returns the object with the given distinguished name.
+func (d *Directory) Open(dn string) (directory.Object, error) {
+	if dn == "" {
+		return d.RootDSE()
+	}
+	d.mutex.RLock()
+	entry, ok := d.entries[key(dn)]
+	d.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", directory.ErrNotFound, dn)
+	}
+	return &object{dir: d, entry: entry}, nil
+}
+
+// Search returns the objects of the given class that are beneath the base
+// object, sorted by distinguished name. All attributes are returned.
+func (d *Directory) Search(ctx context.Context, base, class string, attributes []string) (objects []directory.Object, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	suffix := "," + key(base)
+
+	var entries []*Entry
+	d.mutex.RLock()
+	for k, entry := range d.entries {
+		if !strings.HasSuffix(k, suffix) {
+			continue
+		}
+		for _, c := range entry.values("objectClass") {
+			if strings.EqualFold(c, class) {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	d.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return key(entries[i].DN) < key(entries[j].DN)
+	})
+	for _, entry := range entries {
+		objects = append(objects, &object{dir: d, entry: entry})
+	}
+	return
+}
+
+// RootDSE returns the root DSE of the directory.
+func (d *Directory) RootDSE() (directory.Object, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return &object{dir: d, entry: d.rootDSE}, nil
+}
+
+// Close does nothing. The contents of the directory are retained.
+func (d *Directory) Close() {
+}
+
+// children returns the immediate children of the given distinguished name,
+// sorted by distinguished name.
+func (d *Directory) children(dn string) (entries []*Entry) {
+	parent := key(dn)
+
+	d.mutex.RLock()
+	for k, entry := range d.entries {
+		if _, p := directory.SplitDN(k); p == parent {
+			entries = append(entries, entry)
+		}
+	}
+	d.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return key(entries[i].DN) < key(entries[j].DN)
+	})
+	return
+}
+
+// object is a directory object backed by an entry.
+type object struct {
+	dir   *Directory
+	entry *Entry
+}
+
+func (o *object) Name() (string, error) {
+	rdn, _ := directory.SplitDN(o.entry.DN)
+	return rdn, nil
+}
+
+func (o *object) Class() (string, error) {
+	classes := o.entry.values("objectClass")
+	if len(classes) == 0 {
+		return "", fmt.Errorf("%w: objectClass", directory.ErrNotFound)
+	}
+	return classes[len(classes)-1], nil
+}
+
+func (o *object) GUID() (*ole.GUID, error) {
+	value := o.entry.value("objectGUID")
+	if len(value) == 16 {
+		return directory.ParseGUID([]byte(value))
+	}
+	if guid := ole.NewGUID(value); guid != nil {
+		return guid, nil
+	}
+	return nil, directory.ErrInvalidGUID
+}
+
+func (o *object) DN() (string, error) {
+	return o.entry.DN, nil
+}
+
+func (o *object) Parent() (string, error) {
+	_, parent := directory.SplitDN(o.entry.DN)
+	return parent, nil
+}
+
+func (o *object) AttrString(name string) (string, error) {
+	return o.entry.value(name), nil
+}
+
+func (o *object) AttrStrings(name string) ([]string, error) {
+	if values := o.entry.values(name); len(values) > 0 {
+		return append([]string(nil), values...), nil
+	}
+	return nil, nil
+}
+
+func (o *object) AttrBool(name string) (bool, error) {
+	value := o.entry.value(name)
+	if value == "" {
+		return false, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseBool(value)
+}
+
+func (o *object) AttrInt(name string) (int, error) {
+	value := o.entry.value(name)
+	if value == "" {
+		return 0, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseInt(value)
+}
+
+func (o *object) AttrInt64(name string) (int64, error) {
+	value := o.entry.value(name)
+	if value == "" {
+		return 0, fmt.Errorf("%w: %s", directory.ErrNotFound, name)
+	}
+	return directory.ParseInt64(value)
+}
+
+func (o *object) AttrBytes(name string) ([]byte, error) {
+	if values := o.entry.values(name); len(values) > 0 {
+		return []byte(values[0]), nil
+	}
+	return nil, nil
+}
+
+func (o *object) Children() (directory.Iterator, error) {
+	return &iterator{dir: o.dir, entries: o.dir.children(o.entry.DN)}, nil
+}
+
+func (o *object) Child(class, rdn string) (directory.Object, error) {
+	child, err := o.dir.Open(rdn + "," + o.entry.DN)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range child.(*object).entry.values("objectClass") {
+		if strings.EqualFold(c, class) {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is not of class %s", directory.ErrNotFound, rdn, class)
+}
+
+func (o *object) Close() {
+}
+
+// iterator iterates over a set of entries.
+type iterator struct {
+	dir     *Directory
+	entries []*Entry
+}
+
+func (i *iterator) Next() (directory.Object, error) {
+	if len(i.entries) == 0 {
+		return nil, directory.ErrDone
+	}
+	entry := i.entries[0]
+	i.entries = i.entries[1:]
+	return &object{dir: i.dir, entry: entry}, nil
+}
+
+func (i *iterator) Close() {
+	i.entries = nil
+}
+
+// key returns the normalized form of a distinguished name.
+func key(dn string) string {
+	var parts []string
+	for dn != "" {
+		var rdn string
+		rdn, dn = directory.SplitDN(dn)
+		parts = append(parts, strings.ToLower(rdn))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,79 @@
+package directory
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/go-ole/go-ole"
+)
+
+// SplitDN splits a distinguished name into its relative distinguished name
+// and the distinguished name of its parent. Escaped commas are handled.
+func SplitDN(dn string) (rdn, parent string) {
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++ // Skip the escaped character
+		case ',':
+			return strings.TrimSpace(dn[:i]), strings.TrimSpace(dn[i+1:])
+		}
+	}
+	return strings.TrimSpace(dn), ""
+}
+
+// ParseGUID converts the binary representation of an objectGUID attribute to
+// a GUID. The first three fields are stored in little-endian byte order.
+func ParseGUID(b []byte) (*ole.GUID, error) {
+	if len(b) != 16 {
+		return nil, ErrInvalidGUID
+	}
+	guid := &ole.GUID{
+		Data1: binary.LittleEndian.Uint32(b[0:4]),
+		Data2: binary.LittleEndian.Uint16(b[4:6]),
+		Data3: binary.LittleEndian.Uint16(b[6:8]),
+	}
+	copy(guid.Data4[:], b[8:16])
+	return guid, nil
+}
+
+// FormatGUID returns the binary representation of a GUID as it is stored in
+// an objectGUID attribute.
+func FormatGUID(guid *ole.GUID) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b[0:4], guid.Data1)
+	binary.LittleEndian.PutUint16(b[4:6], guid.Data2)
+	binary.LittleEndian.PutUint16(b[6:8], guid.Data3)
+	copy(b[8:16], guid.Data4[:])
+	return b
+}
+
+// ParseBool parses an LDAP boolean value, which is either "TRUE" or "FALSE".
+func ParseBool(value string) (bool, error) {
+	switch strings.ToUpper(value) {
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	default:
+		return false, ErrInvalidBool
+	}
+}
+
+// ParseInt parses an LDAP integer value.
+func ParseInt(value string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, ErrInvalidInt
+	}
+	return i, nil
+}
+
+// ParseInt64 parses an LDAP large integer value.
+func ParseInt64(value string) (int64, error) {
+	i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInt
+	}
+	return i, nil
+}
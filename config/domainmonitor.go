@@ -5,9 +5,12 @@ import (
 	"sync"
 	"time"
 
-	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
+	"gopkg.in/dfsr.v0/config/globalsettings"
 	"gopkg.in/dfsr.v0/core"
 	"gopkg.in/dfsr.v0/poller"
+	"gopkg.in/dfsr.v0/topology"
 	"gopkg.in/dfsr.v0/valuesink"
 )
 
@@ -31,7 +34,7 @@ func (bc *domainBroadcaster) Listen() <-chan DomainUpdate {
 	return ch
 }
 
-func (bc *domainBroadcaster) Broadcast(domain *core.Domain, timestamp time.Time, err error) {
+func (bc *domainBroadcaster) Broadcast(domain *core.Domain, changes []topology.Change, timestamp time.Time, err error) {
 	bc.mutex.Lock()
 	defer bc.mutex.Unlock()
 
@@ -42,6 +45,7 @@ func (bc *domainBroadcaster) Broadcast(domain *core.Domain, timestamp time.Time,
 	for _, listener := range bc.listeners {
 		listener <- DomainUpdate{
 			Domain:    domain,
+			Changes:   changes,
 			Timestamp: timestamp,
 			Err:       err,
 		}
@@ -66,27 +70,51 @@ func (bc *domainBroadcaster) Close() {
 // domainSource acts as a polling source for poller.Poller. It retrieves
 // domain configuration data, updates a sink and sends data via a broadcaster.
 type domainSource struct {
-	client *adsi.Client
-	domain string
-	sink   *valuesink.Sink
-	bc     *domainBroadcaster
+	dir     directory.Directory
+	domain  string
+	mode    globalsettings.Mode
+	timeout time.Duration
+	sink    *valuesink.Sink
+	bc      *domainBroadcaster
+	last    *core.Domain // Last configuration successfully retrieved, for comparison
 }
 
 func (ds *domainSource) Poll(ctx context.Context) {
-	// FIXME: Propagate the context
+	fetchCtx := ctx
+	if ds.timeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, ds.timeout)
+		defer cancel()
+	}
+
 	timestamp := time.Now()
-	cfg, err := Domain(ds.client, ds.domain)
+	cfg, err := DomainWithConfig(fetchCtx, ds.dir, ds.domain, globalsettings.Config{Mode: ds.mode})
+	if ctx.Err() != nil {
+		return // The monitor is stopping
+	}
+
+	var changes []topology.Change
+	if err == nil {
+		changes = topology.Diff(ds.last, &cfg)
+		ds.last = retainFailed(ds.last, &cfg)
+	}
 	ds.sink.Update(&cfg, timestamp, err)
-	ds.bc.Broadcast(&cfg, timestamp, err)
+	ds.bc.Broadcast(&cfg, changes, timestamp, err)
 }
 
 func (ds *domainSource) Close() {
-	ds.client.Close()
+	ds.dir.Close()
 }
 
 // DomainUpdate represents an update to domain configuration data.
+//
+// Changes lists the differences from the previous configuration that was
+// successfully retrieved. It is nil for the first successful update and for
+// updates that failed, so listeners that only care about real changes can
+// skip updates without any.
 type DomainUpdate struct {
 	Domain    *core.Domain
+	Changes   []topology.Change
 	Timestamp time.Time
 	Err       error
 }
@@ -100,18 +128,70 @@ type DomainMonitor struct {
 	mutex    sync.Mutex
 	domain   string
 	interval time.Duration
+	timeout  time.Duration
+	mode     globalsettings.Mode
+	open     func() (directory.Directory, error)
 	instance *poller.Poller
 	closed   bool
 }
 
+// DomainMonitorConfig holds optional settings for a domain monitor.
+type DomainMonitorConfig struct {
+	// Open is called each time the monitor is started to open the directory
+	// that it queries. The directory is closed when the monitor is stopped.
+	// If Open is nil, Active Directory is queried through ADSI.
+	Open func() (directory.Directory, error)
+
+	// Timeout limits the duration of each configuration retrieval. If it is
+	// zero retrievals are only abandoned when the monitor is stopped.
+	Timeout time.Duration
+
+	// Mode selects how replication groups are retrieved. ADSI directories
+	// do not support subtree searches, so ModeAuto opens each object
+	// individually unless Open provides a directory from the ldapdir
+	// package, and ModeSearch requires one.
+	Mode globalsettings.Mode
+}
+
 // NewDomainMonitor returns a new DFSR configuration monitor that polls Active
 // Directory for updated DFSR configuration for a domain. If the provided domain
 // is an empty string the monitor will attempt to use the the domain of the
 // computer it is running on by querying the root domain naming context.
 func NewDomainMonitor(domain string, interval time.Duration) *DomainMonitor {
+	return NewDomainMonitorWithConfig(domain, interval, DomainMonitorConfig{})
+}
+
+// NewDomainMonitorWithDirectory returns a new DFSR configuration monitor that
+// polls a directory for updated DFSR configuration for a domain. The provided
+// open function is called each time the monitor is started, and the directory
+// it returns is closed when the monitor is stopped. This allows the monitor to
+// use a directory other than ADSI, such as one provided by the ldapdir
+// package.
+//
+// If the provided domain is an empty string the monitor will attempt to use
+// the root domain naming context of the directory.
+func NewDomainMonitorWithDirectory(open func() (directory.Directory, error), domain string, interval time.Duration) *DomainMonitor {
+	return NewDomainMonitorWithConfig(domain, interval, DomainMonitorConfig{Open: open})
+}
+
+// NewDomainMonitorWithConfig returns a new DFSR configuration monitor for a
+// domain with the given configuration.
+//
+// If the provided domain is an empty string the monitor will attempt to use
+// the root domain naming context of the directory.
+func NewDomainMonitorWithConfig(domain string, interval time.Duration, config DomainMonitorConfig) *DomainMonitor {
+	open := config.Open
+	if open == nil {
+		open = func() (directory.Directory, error) {
+			return adsidir.Open()
+		}
+	}
 	m := &DomainMonitor{
 		domain:   domain,
 		interval: interval,
+		timeout:  config.Timeout,
+		mode:     config.Mode,
+		open:     open,
 	}
 	return m
 }
@@ -139,9 +219,9 @@ func (m *DomainMonitor) Close() {
 }
 
 // Start starts the configuration monitor. If the monitor is already running
-// start does nothing and returns nil. If it is unable to initialize an ADSI
-// client start will return an error. If the monitor is already closed
-// ErrClosed will be returned.
+// start does nothing and returns nil. If it is unable to open the directory
+// start will return an error. If the monitor is already closed ErrClosed will
+// be returned.
 func (m *DomainMonitor) Start() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -152,32 +232,36 @@ func (m *DomainMonitor) Start() error {
 		return nil // Already running
 	}
 
-	client, err := adsi.NewClient()
+	dir, err := m.open()
 	if err != nil {
 		return err
 	}
 	if m.domain == "" {
-		m.domain, err = dnc(client)
+		m.domain, err = dnc(dir)
 		if err != nil {
+			dir.Close()
 			return err
 		}
 		if m.domain == "" {
+			dir.Close()
 			return ErrDomainLookupFailed
 		}
 	}
 
 	m.instance = poller.New(&domainSource{
-		client: client,
-		domain: m.domain,
-		sink:   &m.sink,
-		bc:     &m.bc,
+		dir:     dir,
+		domain:  m.domain,
+		mode:    m.mode,
+		timeout: m.timeout,
+		sink:    &m.sink,
+		bc:      &m.bc,
 	}, m.interval)
 
 	return nil
 }
 
-// Stop stops the monitor and prevents further polling of Active Directory
-// until Start is called again.
+// Stop stops the monitor and prevents further polling of the directory until
+// Start is called again. Any retrieval in progress is abandoned.
 func (m *DomainMonitor) Stop() {
 	m.mutex.Lock()
 	if m.instance != nil {
@@ -191,7 +275,7 @@ func (m *DomainMonitor) Stop() {
 // if it has not yet acquired any data.
 func (m *DomainMonitor) Value() (cfg *core.Domain, timestamp time.Time, err error) {
 	v, timestamp, err := m.sink.Value()
-	cfg = v.(*core.Domain)
+	cfg, _ = v.(*core.Domain)
 	return
 }
 
@@ -203,9 +287,10 @@ func (m *DomainMonitor) Listen() <-chan DomainUpdate {
 }
 
 // WaitReady blocks until the monitor has retrieved configuration data. If the
-// monitor has already retrieved data the call will not block.
-func (m *DomainMonitor) WaitReady() (err error) {
-	return m.sink.WaitReady()
+// monitor has already retrieved data the call will not block. If ctx is
+// cancelled before data is retrieved the context's error is returned.
+func (m *DomainMonitor) WaitReady(ctx context.Context) (err error) {
+	return m.sink.WaitReady(ctx)
 }
 
 // Update requests immediate retrieval of configuration data from Active
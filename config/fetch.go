@@ -1,21 +1,102 @@
 package config
 
 import (
+	"context"
+	"time"
+
 	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/dfsconfig"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
 	"gopkg.in/dfsr.v0/config/globalsettings"
+	"gopkg.in/dfsr.v0/config/sites"
 	"gopkg.in/dfsr.v0/core"
 )
 
 // Domain will fetch DFSR configuration data from the specified domain using the
-// provided ADSI client.
-func Domain(client *adsi.Client, domain string) (data core.Domain, err error) {
-	gs := globalsettings.New(client, domain)
-	return gs.Domain()
+// provided ADSI client. The retrieval is abandoned if ctx is cancelled.
+func Domain(ctx context.Context, client *adsi.Client, domain string) (data core.Domain, err error) {
+	return DomainWithDirectory(ctx, adsidir.New(client), domain)
+}
+
+// DomainWithDirectory will fetch DFSR configuration data from the specified
+// domain using the provided directory. Each group is tagged with the
+// distinguished name of the domain. The sites and site links of the forest
+// are included and each member and connection computer is assigned to its
+// site. The domain-based DFS namespaces of the domain and the SYSVOL migration
+// state of its domain controllers are included as well.
+//
+// If the sites, namespaces or SYSVOL state cannot be retrieved the groups are
+// returned without them and the error is recorded in the SitesErr,
+// NamespacesErr or SysvolErr field of the domain.
+func DomainWithDirectory(ctx context.Context, dir directory.Directory, domain string) (data core.Domain, err error) {
+	return DomainWithConfig(ctx, dir, domain, globalsettings.Config{})
+}
+
+// DomainWithConfig will fetch DFSR configuration data from the specified
+// domain using the provided directory and global settings configuration,
+// which selects how replication groups are retrieved. It returns the same
+// data as DomainWithDirectory.
+//
+// ADSI directories do not implement directory.Searcher, so ModeSearch
+// requires a directory provided by the ldapdir package.
+func DomainWithConfig(ctx context.Context, dir directory.Directory, domain string, config globalsettings.Config) (data core.Domain, err error) {
+	gs := globalsettings.NewWithConfig(dir, domain, config)
+	if data, err = gs.Domain(ctx); err != nil {
+		return
+	}
+	for g := range data.Groups {
+		data.Groups[g].Domain = data.DN
+	}
+
+	start := time.Now()
+	if data.SitesErr = applySites(ctx, dir, &data); data.SitesErr != nil {
+		if err = ctx.Err(); err != nil {
+			return core.Domain{}, err
+		}
+	}
+
+	data.Namespaces, data.NamespacesErr = dfsconfig.NewWithDirectory(dir, domain).Namespaces(ctx)
+	if data.NamespacesErr != nil {
+		if err = ctx.Err(); err != nil {
+			return core.Domain{}, err
+		}
+	}
+	data.ConfigDuration += time.Now().Sub(start)
+	return
+}
+
+// applySites retrieves the sites and site links of the forest from dir and
+// assigns the computers of data to them.
+func applySites(ctx context.Context, dir directory.Directory, data *core.Domain) error {
+	topology, err := sites.NewWithDirectory(dir).Topology(ctx)
+	if err != nil {
+		return err
+	}
+	return topology.Apply(ctx, data)
 }
 
 // Group will fetch DFSR configuration data for the replication group in the
 // specified domain that matches the given name using the provided ADSI client.
-func Group(client *adsi.Client, domain, groupName string) (data core.Group, err error) {
-	gs := globalsettings.New(client, domain)
-	return gs.GroupByName(groupName)
+func Group(ctx context.Context, client *adsi.Client, domain, groupName string) (data core.Group, err error) {
+	return GroupWithDirectory(ctx, adsidir.New(client), domain, groupName)
+}
+
+// GroupWithDirectory will fetch DFSR configuration data for the replication
+// group in the specified domain that matches the given name using the
+// provided directory.
+func GroupWithDirectory(ctx context.Context, dir directory.Directory, domain, groupName string) (data core.Group, err error) {
+	gs := globalsettings.NewWithDirectory(dir, domain)
+	if data, err = gs.GroupByName(ctx, groupName); err != nil {
+		return
+	}
+
+	topology, err := sites.NewWithDirectory(dir).Topology(ctx)
+	if err != nil {
+		return core.Group{}, err
+	}
+	if err = topology.ApplyGroup(ctx, &data); err != nil {
+		return core.Group{}, err
+	}
+	return
 }
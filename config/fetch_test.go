@@ -0,0 +1,305 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/memdir"
+	"gopkg.in/dfsr.v0/config/globalsettings"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/lint"
+)
+
+var errUnreachable = errors.New("unreachable")
+
+// unreachable fails to open any object whose distinguished name has the
+// given prefix. It hides the Searcher implementation of the directory.
+type unreachable struct {
+	directory.Directory
+	prefix string
+}
+
+func (u unreachable) Open(dn string) (directory.Object, error) {
+	if strings.HasPrefix(dn, u.prefix) {
+		return nil, errUnreachable
+	}
+	return u.Directory.Open(dn)
+}
+
+var errCorrupt = errors.New("corrupt")
+
+// corruptComputer is a directory in which the string attributes of the
+// computer object with the given distinguished name cannot be read.
+type corruptComputer struct {
+	*memdir.Directory
+	dn string
+}
+
+type corruptObject struct {
+	directory.Object
+}
+
+func (corruptObject) AttrString(name string) (string, error) {
+	return "", errCorrupt
+}
+
+func (c corruptComputer) wrap(o directory.Object) directory.Object {
+	if dn, _ := o.DN(); strings.EqualFold(dn, c.dn) {
+		return corruptObject{o}
+	}
+	return o
+}
+
+func (c corruptComputer) Open(dn string) (directory.Object, error) {
+	o, err := c.Directory.Open(dn)
+	if err != nil {
+		return nil, err
+	}
+	return c.wrap(o), nil
+}
+
+func (c corruptComputer) Search(ctx context.Context, base, class string, attributes []string) ([]directory.Object, error) {
+	objects, err := c.Directory.Search(ctx, base, class, attributes)
+	for i := range objects {
+		objects[i] = c.wrap(objects[i])
+	}
+	return objects, err
+}
+
+func loadDirectory(t *testing.T, name string) *memdir.Directory {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dir := memdir.New()
+	if err := dir.LoadLDIF(f); err != nil {
+		t.Fatalf("LoadLDIF(%s): %v", name, err)
+	}
+	return dir
+}
+
+func fetchModes(t *testing.T, name string) map[string]core.Domain {
+	t.Helper()
+	dir := loadDirectory(t, name)
+	modes := []globalsettings.Mode{globalsettings.ModeObjects, globalsettings.ModeSearch}
+	domains := make(map[string]core.Domain, len(modes))
+	for _, mode := range modes {
+		domain, err := DomainWithConfig(context.Background(), dir, "example.com", globalsettings.Config{Mode: mode})
+		if err != nil {
+			t.Fatalf("%s: DomainWithConfig: %v", mode, err)
+		}
+		domains[mode.String()] = domain
+	}
+	return domains
+}
+
+func findGroup(domain *core.Domain, name string) *core.Group {
+	for g := range domain.Groups {
+		if domain.Groups[g].Name == name {
+			return &domain.Groups[g]
+		}
+	}
+	return nil
+}
+
+func TestDomainWithDirectory(t *testing.T) {
+	for mode, domain := range fetchModes(t, "domain.ldif") {
+		if domain.DN != "DC=example,DC=com" {
+			t.Errorf("%s: DN = %s, want DC=example,DC=com", mode, domain.DN)
+		}
+		if len(domain.Groups) != 3 {
+			t.Fatalf("%s: len(Groups) = %d, want 3", mode, len(domain.Groups))
+		}
+
+		group := findGroup(&domain, "Data")
+		if group == nil {
+			t.Fatalf("%s: group Data not found", mode)
+		}
+		if group.Err != nil {
+			t.Fatalf("%s: group Data failed: %v", mode, group.Err)
+		}
+		if group.Domain != "DC=example,DC=com" {
+			t.Errorf("%s: group Domain = %s, want DC=example,DC=com", mode, group.Domain)
+		}
+		if len(group.Folders) != 1 || group.Folders[0].Name != "Share" {
+			t.Errorf("%s: Folders = %+v, want Share", mode, group.Folders)
+		}
+		if len(group.Members) != 2 {
+			t.Fatalf("%s: len(Members) = %d, want 2", mode, len(group.Members))
+		}
+
+		hosts := map[string]string{"M1": "fs1.example.com", "M2": "fs2.example.com"}
+		for _, member := range group.Members {
+			if want := hosts[member.Name]; member.Computer.Host != want {
+				t.Errorf("%s: member %s host = %s, want %s", mode, member.Name, member.Computer.Host, want)
+			}
+			if len(member.Connections) != 1 {
+				t.Errorf("%s: member %s has %d connections, want 1", mode, member.Name, len(member.Connections))
+				continue
+			}
+			conn := member.Connections[0]
+			switch member.Name {
+			case "M1":
+				if conn.Name != "C1" || !conn.Enabled || conn.Computer.Host != "fs2.example.com" {
+					t.Errorf("%s: connection = %s enabled %v from %s, want C1 enabled from fs2.example.com", mode, conn.Name, conn.Enabled, conn.Computer.Host)
+				}
+			case "M2":
+				if conn.Name != "C2" || conn.Enabled || conn.Computer.Host != "fs1.example.com" {
+					t.Errorf("%s: connection = %s enabled %v from %s, want C2 disabled from fs1.example.com", mode, conn.Name, conn.Enabled, conn.Computer.Host)
+				}
+			}
+		}
+
+		if len(domain.Sites) != 2 || len(domain.SiteLinks) != 1 {
+			t.Errorf("%s: %d sites and %d site links, want 2 and 1", mode, len(domain.Sites), len(domain.SiteLinks))
+		}
+		for _, member := range group.Members {
+			if member.Name == "M1" && member.Computer.Site != "Hub" {
+				t.Errorf("%s: member M1 site = %q, want Hub", mode, member.Computer.Site)
+			}
+		}
+	}
+}
+
+func TestDomainWithDirectoryPartial(t *testing.T) {
+	for mode, domain := range fetchModes(t, "domain.ldif") {
+		broken := findGroup(&domain, "Broken")
+		if broken == nil {
+			t.Fatalf("%s: group Broken not found", mode)
+		}
+		if broken.Err == nil {
+			t.Errorf("%s: group Broken has no error", mode)
+		}
+		if failed := domain.Failed(); len(failed) != 1 || failed[0].Name != "Broken" {
+			t.Errorf("%s: Failed = %v, want Broken", mode, failed)
+		}
+		if data := findGroup(&domain, "Data"); data == nil || data.Err != nil {
+			t.Errorf("%s: group Data was not retrieved alongside the broken group", mode)
+		}
+	}
+}
+
+func TestDomainWithDirectoryCorruptComputer(t *testing.T) {
+	dir := corruptComputer{loadDirectory(t, "domain.ldif"), "CN=FS2,CN=Computers,DC=example,DC=com"}
+	for _, mode := range []globalsettings.Mode{globalsettings.ModeObjects, globalsettings.ModeSearch} {
+		domain, err := DomainWithConfig(context.Background(), dir, "example.com", globalsettings.Config{Mode: mode})
+		if err != nil {
+			t.Fatalf("%s: DomainWithConfig: %v", mode, err)
+		}
+		if data := findGroup(&domain, "Data"); data == nil || !errors.Is(data.Err, errCorrupt) {
+			t.Errorf("%s: group Data was not failed by the computer of its member: %+v", mode, data)
+		}
+		if orphan := findGroup(&domain, "Orphan"); orphan == nil || orphan.Err != nil {
+			t.Errorf("%s: group Orphan was failed by the computer of another group: %+v", mode, orphan)
+		}
+	}
+}
+
+func TestDomainWithDirectoryModesAgree(t *testing.T) {
+	domains := fetchModes(t, "domain.ldif")
+
+	encode := func(domain core.Domain) string {
+		domain.ConfigDuration = 0
+		for g := range domain.Groups {
+			domain.Groups[g].ConfigDuration = 0
+			if domain.Groups[g].Err != nil {
+				// The wording of errors depends on how the group was read
+				domain.Groups[g] = core.Group{Name: domain.Groups[g].Name, ID: domain.Groups[g].ID}
+			}
+		}
+		data, err := json.Marshal(&domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if objects, search := encode(domains["objects"]), encode(domains["search"]); objects != search {
+		t.Errorf("configuration differs between modes:\nobjects: %s\nsearch:  %s", objects, search)
+	}
+}
+
+func TestDomainWithDirectorySitesFailure(t *testing.T) {
+	dir := unreachable{loadDirectory(t, "domain.ldif"), "CN=Sites,"}
+	domain, err := DomainWithDirectory(context.Background(), dir, "example.com")
+	if err != nil {
+		t.Fatalf("DomainWithDirectory: %v", err)
+	}
+	if !errors.Is(domain.SitesErr, errUnreachable) {
+		t.Errorf("SitesErr = %v, want %v", domain.SitesErr, errUnreachable)
+	}
+	if len(domain.Sites) != 0 {
+		t.Errorf("len(Sites) = %d, want 0", len(domain.Sites))
+	}
+	if group := findGroup(&domain, "Data"); group == nil || group.Err != nil || len(group.Members) != 2 {
+		t.Errorf("group Data was not retrieved without sites: %+v", group)
+	}
+}
+
+func TestDomainWithDirectoryNamespacesFailure(t *testing.T) {
+	dir := unreachable{loadDirectory(t, "domain.ldif"), "CN=Dfs-Configuration,"}
+	domain, err := DomainWithDirectory(context.Background(), dir, "example.com")
+	if err != nil {
+		t.Fatalf("DomainWithDirectory: %v", err)
+	}
+	if !errors.Is(domain.NamespacesErr, errUnreachable) {
+		t.Errorf("NamespacesErr = %v, want %v", domain.NamespacesErr, errUnreachable)
+	}
+	if group := findGroup(&domain, "Data"); group == nil || group.Err != nil || len(group.Members) != 2 {
+		t.Errorf("group Data was not retrieved without namespaces: %+v", group)
+	}
+}
+
+func TestDomainWithDirectorySysvolFailure(t *testing.T) {
+	dir := unreachable{loadDirectory(t, "domain.ldif"), "OU=Domain Controllers,"}
+	domain, err := DomainWithDirectory(context.Background(), dir, "example.com")
+	if err != nil {
+		t.Fatalf("DomainWithDirectory: %v", err)
+	}
+	if !errors.Is(domain.SysvolErr, errUnreachable) {
+		t.Errorf("SysvolErr = %v, want %v", domain.SysvolErr, errUnreachable)
+	}
+	if domain.Sysvol != nil {
+		t.Errorf("Sysvol = %+v, want nil", domain.Sysvol)
+	}
+	if group := findGroup(&domain, "Data"); group == nil || group.Err != nil || len(group.Members) != 2 {
+		t.Errorf("group Data was not retrieved without the SYSVOL state: %+v", group)
+	}
+}
+
+func TestDomainWithDirectoryUnresolvedSource(t *testing.T) {
+	for mode, domain := range fetchModes(t, "domain.ldif") {
+		group := findGroup(&domain, "Orphan")
+		if group == nil {
+			t.Fatalf("%s: group Orphan not found", mode)
+		}
+		if group.Err != nil {
+			t.Fatalf("%s: group Orphan failed: %v", mode, group.Err)
+		}
+		if len(group.Members) != 1 || len(group.Members[0].Connections) != 1 {
+			t.Fatalf("%s: group Orphan has unexpected members: %+v", mode, group.Members)
+		}
+		if conn := group.Members[0].Connections[0]; conn.Computer != (core.Computer{}) {
+			t.Errorf("%s: connection computer = %+v, want none", mode, conn.Computer)
+		}
+
+		var found bool
+		for _, finding := range lint.Group(group) {
+			if finding.Code == lint.UnknownSource && finding.Connection == "C3" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: connection C3 was not reported as %s", mode, lint.UnknownSource)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package fileconfig
+
+import "errors"
+
+const updateChanSize = 16
+
+var (
+	// ErrClosed is returned from calls to a service or interface in the event
+	// that the Close() function has already been called.
+	ErrClosed = errors.New("Interface is closing or already closed.")
+
+	// ErrDuplicateID is returned when two objects in a topology file share the
+	// same GUID.
+	ErrDuplicateID = errors.New("The topology contains a duplicate GUID.")
+
+	// ErrUnknownMember is returned when a connection in a topology file refers
+	// to a member that is not part of its replication group.
+	ErrUnknownMember = errors.New("The connection refers to an unknown member.")
+
+	// ErrMissingHost is returned when a member or connection in a topology file
+	// does not have a host name.
+	ErrMissingHost = errors.New("The host name is missing.")
+)
@@ -0,0 +1,37 @@
+// Package fileconfig provides a DFSR configuration source that is loaded from
+// a hand-maintained JSON or YAML topology file instead of Active Directory.
+//
+// The file contains a core.Domain in the encoding provided by the core
+// package. Connections may refer to their source member by its distinguished
+// name, by its host name or both; missing values are filled in from the
+// member when the file is loaded:
+//
+//   dn: DC=example,DC=com
+//   groups:
+//   - name: Data
+//     folders:
+//     - name: Shared
+//     members:
+//     - name: FS1
+//       computer:
+//         host: fs1.example.com
+//       connections:
+//       - name: FS2 to FS1
+//         enabled: true
+//         computer:
+//           host: fs2.example.com
+//     - name: FS2
+//       computer:
+//         host: fs2.example.com
+//
+// A Monitor watches the file for changes and can be used as a monitor.Source,
+// which allows the backlog monitor to run in labs, in tests and on systems
+// that are not joined to a domain:
+//
+//   cfg := fileconfig.New("topology.yaml", time.Minute)
+//   if err := cfg.Start(); err != nil {
+//     return err
+//   }
+//   defer cfg.Close()
+//   mon := monitor.New(cfg, 5*time.Minute, 30*time.Second, 1)
+package fileconfig
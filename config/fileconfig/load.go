@@ -0,0 +1,105 @@
+package fileconfig
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is the encoding of a topology file.
+type Format int
+
+// Topology file formats.
+const (
+	YAML Format = iota
+	JSON
+)
+
+// FormatOf returns the format of the topology file at the given path, which
+// is determined by its extension. Files without a .json extension are assumed
+// to be YAML.
+func FormatOf(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return JSON
+	}
+	return YAML
+}
+
+// Load reads and decodes the topology file at the given path. The returned
+// domain has been resolved and validated.
+func Load(path string) (domain *core.Domain, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	return Decode(data, FormatOf(path))
+}
+
+// Decode decodes a topology in the given format. Missing connection details
+// are filled in from the members they refer to and the result is validated
+// before it is returned.
+func Decode(data []byte, format Format) (domain *core.Domain, err error) {
+	domain = new(core.Domain)
+	switch format {
+	case JSON:
+		err = json.Unmarshal(data, domain)
+	default:
+		err = yaml.Unmarshal(data, domain)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resolve(domain)
+
+	if err = Validate(domain); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// resolve fills in the member distinguished name and computer of connections
+// that only refer to their source member by one or the other.
+func resolve(domain *core.Domain) {
+	for g := range domain.Groups {
+		group := &domain.Groups[g]
+		for m := range group.Members {
+			member := &group.Members[m]
+			for c := range member.Connections {
+				conn := &member.Connections[c]
+				source := findMember(group, conn)
+				if source == nil {
+					continue
+				}
+				if conn.MemberDN == "" {
+					conn.MemberDN = source.DN
+				}
+				if conn.Computer.Host == "" {
+					conn.Computer = source.Computer
+				}
+			}
+		}
+	}
+}
+
+// findMember returns the member of the group that is the source of the given
+// connection, or nil if there isn't one.
+func findMember(group *core.Group, conn *core.Connection) *core.Member {
+	for m := range group.Members {
+		member := &group.Members[m]
+		if conn.MemberDN != "" {
+			if strings.EqualFold(member.DN, conn.MemberDN) {
+				return member
+			}
+			continue
+		}
+		if conn.Computer.Host != "" && strings.EqualFold(member.Computer.Host, conn.Computer.Host) {
+			return member
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package fileconfig
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/monitor"
+	"gopkg.in/dfsr.v0/poller"
+	"gopkg.in/dfsr.v0/topology"
+	"gopkg.in/dfsr.v0/valuesink"
+)
+
+var _ = (monitor.Source)((*Monitor)(nil))
+
+// Update represents an update to the configuration data loaded from a
+// topology file.
+//
+// Changes lists the differences from the previous configuration that was
+// successfully loaded. It is nil for the first successful update and for
+// updates that failed.
+type Update struct {
+	Domain    *core.Domain
+	Changes   []topology.Change
+	Timestamp time.Time
+	Err       error
+}
+
+// broadcaster broadcasts configuration updates to a set of listeners.
+type broadcaster struct {
+	mutex     sync.RWMutex
+	listeners []chan<- Update
+	closed    bool
+}
+
+func (bc *broadcaster) Listen() <-chan Update {
+	ch := make(chan Update, updateChanSize)
+	bc.mutex.Lock()
+	if !bc.closed {
+		bc.listeners = append(bc.listeners, ch)
+	} else {
+		close(ch)
+	}
+	bc.mutex.Unlock()
+	return ch
+}
+
+func (bc *broadcaster) Broadcast(domain *core.Domain, changes []topology.Change, timestamp time.Time, err error) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	for _, listener := range bc.listeners {
+		listener <- Update{
+			Domain:    domain,
+			Changes:   changes,
+			Timestamp: timestamp,
+			Err:       err,
+		}
+	}
+}
+
+func (bc *broadcaster) Close() {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	if bc.closed {
+		return
+	}
+	bc.closed = true
+
+	for _, ch := range bc.listeners {
+		close(ch)
+	}
+	bc.listeners = nil
+}
+
+// fileState describes the state of a topology file when it was last loaded.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// fileSource acts as a polling source for poller.Poller. It reloads the
+// topology file whenever its size or modification time changes, or when the
+// previous load failed, updates a sink and sends data via a broadcaster.
+type fileSource struct {
+	path string
+	sink *valuesink.Sink
+	bc   *broadcaster
+
+	mutex  sync.Mutex
+	last   fileState
+	loaded bool         // Indicates that last is valid
+	domain *core.Domain // Last configuration successfully loaded
+}
+
+func (fs *fileSource) Poll(ctx context.Context) {
+	var current fileState
+	info, statErr := os.Stat(fs.path)
+	if statErr == nil {
+		current = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+
+	fs.mutex.Lock()
+	unchanged := fs.loaded && fs.last == current
+	fs.mutex.Unlock()
+
+	if unchanged {
+		return
+	}
+
+	timestamp := time.Now()
+	domain, err := Load(fs.path)
+	var changes []topology.Change
+	if err == nil {
+		changes = topology.Diff(fs.domain, domain)
+		fs.domain = domain
+
+		// Only a successful load is recorded, so that a file that could not
+		// be read is tried again on the next poll
+		fs.mutex.Lock()
+		fs.last, fs.loaded = current, true
+		fs.mutex.Unlock()
+	}
+	fs.sink.Update(domain, timestamp, err)
+	fs.bc.Broadcast(domain, changes, timestamp, err)
+}
+
+// Reset causes the file to be reloaded on the next poll even if it has not
+// changed.
+func (fs *fileSource) Reset() {
+	fs.mutex.Lock()
+	fs.loaded = false
+	fs.mutex.Unlock()
+}
+
+func (fs *fileSource) Close() {
+}
+
+// Monitor watches a topology file for changes to DFSR configuration.
+type Monitor struct {
+	sink valuesink.Sink // Holds last configuration successfully loaded
+	bc   broadcaster    // Broadcasts configuration updates
+
+	mutex    sync.Mutex
+	path     string
+	interval time.Duration
+	source   *fileSource
+	instance *poller.Poller
+	closed   bool
+}
+
+// New returns a new DFSR configuration monitor that loads configuration from
+// the topology file at the given path. The file is checked for changes at the
+// provided interval and reloaded when its size or modification time changes.
+func New(path string, interval time.Duration) *Monitor {
+	return &Monitor{
+		path:     path,
+		interval: interval,
+	}
+}
+
+// Close will release resources consumed by the monitor. It should be called
+// when finished with the monitor. Calling close will prevent future calls to
+// start or update from succeeding. Close will not return until all
+// monitor-related goroutines have exited.
+func (m *Monitor) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return
+	}
+	m.closed = true
+
+	if m.instance != nil {
+		m.instance.Close() // Blocks until the instance completely winds down
+		m.instance = nil
+	}
+
+	m.sink.Close()
+	m.bc.Close()
+}
+
+// Start starts watching the topology file and requests that it be loaded
+// immediately. If the monitor is already running start does nothing and
+// returns nil. If the monitor is already closed ErrClosed will be returned.
+func (m *Monitor) Start() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	if m.instance != nil {
+		return nil // Already running
+	}
+
+	m.source = &fileSource{
+		path: m.path,
+		sink: &m.sink,
+		bc:   &m.bc,
+	}
+	m.instance = poller.New(m.source, m.interval)
+	m.instance.Poll()
+
+	return nil
+}
+
+// Stop stops the monitor and prevents further checks of the topology file
+// until Start is called again.
+func (m *Monitor) Stop() {
+	m.mutex.Lock()
+	if m.instance != nil {
+		m.instance.Close()
+		m.instance = nil
+	}
+	m.mutex.Unlock()
+}
+
+// Value returns the most recently loaded domain configuration data, or nil
+// if it has not yet loaded any data.
+func (m *Monitor) Value() (cfg *core.Domain, timestamp time.Time, err error) {
+	v, timestamp, err := m.sink.Value()
+	cfg, _ = v.(*core.Domain)
+	return
+}
+
+// Listen returns a channel on which configuration updates will be broadcast.
+// The channel will be closed when the monitor is closed. If the monitor has
+// already been closed then the returned channel will be closed already.
+func (m *Monitor) Listen() <-chan Update {
+	return m.bc.Listen()
+}
+
+// WaitReady blocks until the monitor has loaded configuration data. If the
+// monitor has already loaded data the call will not block. If ctx is
+// cancelled before data is loaded the context's error is returned.
+func (m *Monitor) WaitReady(ctx context.Context) (err error) {
+	return m.sink.WaitReady(ctx)
+}
+
+// Update requests that the topology file be reloaded immediately, even if it
+// has not changed. It does not wait for the reload to complete.
+//
+// If the monitor has not been started Update will do nothing.
+func (m *Monitor) Update() {
+	m.mutex.Lock()
+	if !m.closed && m.instance != nil {
+		m.source.Reset()
+		m.instance.Poll()
+	}
+	m.mutex.Unlock()
+}
@@ -0,0 +1,122 @@
+package fileconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+)
+
+// ValidationError is returned when a topology fails validation. It contains
+// every problem that was found.
+type ValidationError struct {
+	Problems []error
+}
+
+// Error returns a description of the problems.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("invalid topology: %s", strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the problems match target.
+func (e *ValidationError) Is(target error) bool {
+	for _, problem := range e.Problems {
+		if errors.Is(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Problem describes a single validation problem and where it was found.
+type Problem struct {
+	Path string // Location of the problem, such as "Data/FS1/FS2 to FS1"
+	Err  error
+}
+
+// Error returns a description of the problem.
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// Unwrap returns the underlying error.
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// Validate checks the given domain for duplicate GUIDs, connections to
+// members that are not part of the replication group and members or
+// connections that do not have a host name. It returns a *ValidationError if
+// any problems are found.
+func Validate(domain *core.Domain) error {
+	var (
+		problems []error
+		seen     = make(map[string]string) // Maps GUIDs to the path of their first occurrence
+	)
+
+	report := func(path string, err error) {
+		problems = append(problems, &Problem{Path: path, Err: err})
+	}
+
+	check := func(path string, id fmt.Stringer) {
+		key := strings.ToUpper(id.String())
+		if first, ok := seen[key]; ok {
+			report(path, fmt.Errorf("%w (%s is also used by %s)", ErrDuplicateID, key, first))
+			return
+		}
+		seen[key] = path
+	}
+
+	if domain.ID != nil {
+		check(domain.DN, domain.ID)
+	}
+
+	for g := range domain.Groups {
+		group := &domain.Groups[g]
+		if group.ID != nil {
+			check(group.Name, group.ID)
+		}
+		for f := range group.Folders {
+			folder := &group.Folders[f]
+			if folder.ID != nil {
+				check(join(group.Name, folder.Name), folder.ID)
+			}
+		}
+		for m := range group.Members {
+			member := &group.Members[m]
+			path := join(group.Name, member.Name)
+			if member.ID != nil {
+				check(path, member.ID)
+			}
+			if member.Computer.Host == "" {
+				report(path, ErrMissingHost)
+			}
+			for c := range member.Connections {
+				conn := &member.Connections[c]
+				path := join(group.Name, member.Name, conn.Name)
+				if conn.ID != nil {
+					check(path, conn.ID)
+				}
+				if findMember(group, conn) == nil {
+					report(path, ErrUnknownMember)
+				} else if conn.Computer.Host == "" {
+					report(path, ErrMissingHost)
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func join(elements ...string) string {
+	return strings.Join(elements, "/")
+}
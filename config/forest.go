@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gopkg.in/dfsr.v0/config/directory"
+)
+
+// Domains returns the distinguished names of the domains in the forest of the
+// provided directory. The forest root domain is listed first.
+//
+// The domains are enumerated from the crossRef objects in the partitions
+// container of the configuration partition, which is replicated to every
+// domain controller in the forest.
+func Domains(ctx context.Context, dir directory.Directory) (domains []string, err error) {
+	rootDSE, err := dir.RootDSE()
+	if err != nil {
+		return
+	}
+	defer rootDSE.Close()
+
+	configDN, err := rootDSE.AttrString("configurationNamingContext")
+	if err != nil {
+		return
+	}
+	if configDN == "" {
+		return nil, ErrDomainLookupFailed
+	}
+
+	rootDN, err := rootDSE.AttrString("rootDomainNamingContext")
+	if err != nil {
+		return
+	}
+
+	partitions, err := dir.Open("CN=Partitions," + configDN)
+	if err != nil {
+		return
+	}
+	defer partitions.Close()
+
+	iter, err := partitions.Children()
+	if err != nil {
+		return
+	}
+	defer iter.Close()
+
+	for {
+		ref, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer ref.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		class, err := ref.Class()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(class, "crossRef") {
+			continue
+		}
+
+		flags, err := ref.AttrInt("systemFlags")
+		if errors.Is(err, directory.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if flags&crossRefDomain == 0 {
+			continue // Application partitions and external references
+		}
+
+		nc, err := ref.AttrString("nCName")
+		if err != nil {
+			return nil, err
+		}
+		if nc == "" {
+			continue
+		}
+
+		if strings.EqualFold(nc, rootDN) {
+			domains = append([]string{nc}, domains...)
+		} else {
+			domains = append(domains, nc)
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil, ErrDomainLookupFailed
+	}
+	return domains, nil
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
+	"gopkg.in/dfsr.v0/config/globalsettings"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// ForestMonitor polls Active Directory for updated DFSR configuration in
+// several domains, typically every domain in a forest. It runs a domain monitor
+// for each domain and merges their configuration into a single view, so that
+// replication groups that span domains can be monitored by one service.
+type ForestMonitor struct {
+	bc domainBroadcaster // Forwards configuration updates of each domain
+
+	mutex      sync.Mutex
+	domains    []string
+	interval   time.Duration
+	timeout    time.Duration
+	mode       globalsettings.Mode
+	open       func(domain string) (directory.Directory, error)
+	monitors   []*DomainMonitor
+	errs       []error // Start failure of each domain monitor, nil if started
+	forwarders sync.WaitGroup
+	closed     bool
+}
+
+// ForestMonitorConfig holds optional settings for a forest monitor.
+type ForestMonitorConfig struct {
+	// Open is called to open the directory that is queried for a domain. It
+	// is called with an empty domain to open the directory from which the
+	// domains of the forest are enumerated. If Open is nil, Active Directory
+	// is queried through ADSI.
+	Open func(domain string) (directory.Directory, error)
+
+	// Timeout limits the duration of each configuration retrieval in each
+	// domain. If it is zero retrievals are only abandoned when the monitor is
+	// stopped.
+	Timeout time.Duration
+
+	// Mode selects how replication groups are retrieved in each domain. See
+	// DomainMonitorConfig.
+	Mode globalsettings.Mode
+}
+
+// NewForestMonitor returns a new DFSR configuration monitor that polls Active
+// Directory for updated DFSR configuration in the given domains. If no domains
+// are provided the monitor will enumerate the domains of the forest of the
+// computer it is running on when it is first started.
+func NewForestMonitor(domains []string, interval time.Duration) *ForestMonitor {
+	return NewForestMonitorWithConfig(domains, interval, ForestMonitorConfig{})
+}
+
+// NewForestMonitorWithConfig returns a new DFSR configuration monitor for the
+// given domains with the given configuration.
+//
+// If no domains are provided the monitor will enumerate the domains of the
+// forest when it is first started.
+func NewForestMonitorWithConfig(domains []string, interval time.Duration, config ForestMonitorConfig) *ForestMonitor {
+	open := config.Open
+	if open == nil {
+		open = func(string) (directory.Directory, error) {
+			return adsidir.Open()
+		}
+	}
+	return &ForestMonitor{
+		domains:  domains,
+		interval: interval,
+		timeout:  config.Timeout,
+		mode:     config.Mode,
+		open:     open,
+	}
+}
+
+// Close will release resources consumed by the monitor. It should be called
+// when finished with the monitor. Calling close will prevent future calls to
+// start or update from succeeding. Close will not return until all
+// monitor-related goroutines have exited.
+func (f *ForestMonitor) Close() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return
+	}
+	f.closed = true
+
+	for _, m := range f.monitors {
+		m.Close() // Closes the update channel of each forwarder
+	}
+	f.forwarders.Wait()
+
+	f.bc.Close()
+}
+
+// Start starts the configuration monitor. If the monitor is already running
+// start does nothing and returns nil. If the monitor is already closed
+// ErrClosed will be returned.
+//
+// The first time the monitor is started without a list of domains it
+// enumerates the domains of the forest. If the enumeration fails start will
+// return an error. A domain whose directory cannot be opened does not prevent
+// the others from being monitored; its error is reported by Failures and
+// calling start again retries it. Start only returns an error for the domains
+// if none of them could be started.
+func (f *ForestMonitor) Start() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+
+	if f.monitors == nil {
+		domains := f.domains
+		if len(domains) == 0 {
+			dir, err := f.open("")
+			if err != nil {
+				return err
+			}
+			domains, err = Domains(context.Background(), dir)
+			dir.Close()
+			if err != nil {
+				return err
+			}
+			f.domains = domains
+		}
+		for _, domain := range domains {
+			f.monitors = append(f.monitors, f.domainMonitor(domain))
+		}
+		f.errs = make([]error, len(f.monitors))
+	}
+
+	started := 0
+	for d, m := range f.monitors {
+		if f.errs[d] = m.Start(); f.errs[d] == nil {
+			started++
+		}
+	}
+	if started == 0 && len(f.monitors) > 0 {
+		return fmt.Errorf("%w (domain %s)", f.errs[0], f.domains[0])
+	}
+
+	return nil
+}
+
+// domainMonitor returns a new monitor for the given domain and forwards its
+// updates to the listeners of f.
+func (f *ForestMonitor) domainMonitor(domain string) *DomainMonitor {
+	m := NewDomainMonitorWithConfig(domain, f.interval, DomainMonitorConfig{
+		Open: func() (directory.Directory, error) {
+			return f.open(domain)
+		},
+		Timeout: f.timeout,
+		Mode:    f.mode,
+	})
+
+	f.forwarders.Add(1)
+	go func(updates <-chan DomainUpdate) {
+		defer f.forwarders.Done()
+		for update := range updates {
+			f.bc.Broadcast(update.Domain, update.Changes, update.Timestamp, update.Err)
+		}
+	}(m.Listen())
+
+	return m
+}
+
+// Stop stops the monitor and prevents further polling of the directory until
+// Start is called again. Any retrieval in progress is abandoned.
+func (f *ForestMonitor) Stop() {
+	f.mutex.Lock()
+	for _, m := range f.monitors {
+		m.Stop()
+	}
+	f.mutex.Unlock()
+}
+
+// Domains returns the distinguished names of the domains that are monitored.
+// If the monitor enumerates the domains of the forest it returns nil until the
+// monitor has been started.
+func (f *ForestMonitor) Domains() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.domains
+}
+
+// Value returns the most recently retrieved configuration data of all domains
+// merged into a single domain, or nil if it has not yet acquired any data.
+//
+// The merged domain has the naming context of the first domain that has data,
+// which is the forest root domain when the domains are enumerated. It holds
+// the groups and namespaces of every domain, and each group carries the
+// distinguished name of its domain. Sites and site links are shared by the
+// forest and are taken from the first domain. The SYSVOL state is specific to
+// each domain and is left out; it is available from the updates broadcast by
+// Listen.
+//
+// Domains whose configuration is unavailable are left out of the merged
+// domain and reported by Failures instead. The returned timestamp is the
+// oldest of the domains that have data. An error is only returned if no
+// domain has data, in which case it is the first error encountered.
+func (f *ForestMonitor) Value() (cfg *core.Domain, timestamp time.Time, err error) {
+	f.mutex.Lock()
+	monitors, errs := f.monitors, append([]error(nil), f.errs...)
+	f.mutex.Unlock()
+
+	var (
+		merged   core.Domain
+		firstErr error
+	)
+	for d, m := range monitors {
+		domain, ts, derr := m.Value()
+		if derr == nil {
+			derr = errs[d]
+		}
+		if domain == nil {
+			if firstErr == nil {
+				firstErr = derr
+			}
+			continue
+		}
+
+		if cfg == nil {
+			cfg = &merged
+			merged.NamingContext = domain.NamingContext
+			merged.Sites = domain.Sites
+			merged.SiteLinks = domain.SiteLinks
+			merged.SitesErr = domain.SitesErr
+			timestamp = ts
+		} else if ts.Before(timestamp) {
+			timestamp = ts
+		}
+
+		merged.Groups = append(merged.Groups, domain.Groups...)
+		merged.Namespaces = append(merged.Namespaces, domain.Namespaces...)
+		merged.ConfigDuration += domain.ConfigDuration
+	}
+	if cfg == nil {
+		err = firstErr
+	}
+	return
+}
+
+// Failures returns the domains whose configuration is unavailable, mapped to
+// the error that prevented its retrieval. A domain is unavailable if its
+// directory could not be opened or if its configuration could not be
+// retrieved since the monitor was started. Domains whose configuration has
+// not been retrieved yet are not included.
+func (f *ForestMonitor) Failures() map[string]error {
+	f.mutex.Lock()
+	monitors, errs, domains := f.monitors, append([]error(nil), f.errs...), f.domains
+	f.mutex.Unlock()
+
+	failures := make(map[string]error)
+	for d, m := range monitors {
+		err := errs[d]
+		if err == nil {
+			if domain, _, derr := m.Value(); domain == nil {
+				err = derr
+			}
+		}
+		if err != nil {
+			failures[domains[d]] = err
+		}
+	}
+	return failures
+}
+
+// Listen returns a channel on which configuration updates will be broadcast.
+// Each update holds the configuration of a single domain. The channel will be
+// closed when the monitor is closed. If the monitor has already been closed
+// then the returned channel will be closed already.
+func (f *ForestMonitor) Listen() <-chan DomainUpdate {
+	return f.bc.Listen()
+}
+
+// WaitReady blocks until the monitor has retrieved configuration data for
+// every domain or the retrieval has failed. If the monitor has already
+// retrieved data the call will not block. An error is only returned if no
+// domain has data, in which case it is the first error encountered; the
+// failures of individual domains are reported by Failures. If ctx is
+// cancelled before data is retrieved the context's error is returned.
+//
+// WaitReady should be called after the monitor has been started, because the
+// domains of the forest are not known before then.
+func (f *ForestMonitor) WaitReady(ctx context.Context) (err error) {
+	f.mutex.Lock()
+	monitors, errs, closed := f.monitors, append([]error(nil), f.errs...), f.closed
+	f.mutex.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+	if monitors == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ready := false
+	for d, m := range monitors {
+		werr := errs[d]
+		if werr == nil {
+			werr = m.WaitReady(ctx)
+		}
+		switch {
+		case werr == nil:
+			ready = true
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == nil:
+			err = werr
+		}
+	}
+	if ready {
+		return nil
+	}
+	return
+}
+
+// Update requests immediate retrieval of configuration data in every domain.
+// It does not wait for the retrieval to complete.
+//
+// If the monitor has not been started Update will do nothing.
+func (f *ForestMonitor) Update() {
+	f.mutex.Lock()
+	if !f.closed {
+		for _, m := range f.monitors {
+			m.Update()
+		}
+	}
+	f.mutex.Unlock()
+}
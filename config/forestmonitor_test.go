@@ -0,0 +1,33 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"gopkg.in/dfsr.v0/config/directory"
+)
+
+func TestForestMonitorOpensEachDomain(t *testing.T) {
+	domains := []string{"example.com", "child.example.com"}
+
+	var opened []string
+	open := func(domain string) (directory.Directory, error) {
+		opened = append(opened, domain)
+		return loadDirectory(t, "domain.ldif"), nil
+	}
+
+	f := NewForestMonitorWithConfig(domains, time.Hour, ForestMonitorConfig{Open: open})
+	defer f.Close()
+	if err := f.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	want := append([]string(nil), domains...)
+	sort.Strings(want)
+	sort.Strings(opened)
+	if !reflect.DeepEqual(opened, want) {
+		t.Errorf("opened directories for %v, want %v", opened, want)
+	}
+}
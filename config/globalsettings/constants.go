@@ -1,5 +1,79 @@
 package globalsettings
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 const groupQueryDelay = 25 * time.Millisecond // Group query delay to avoid rate-limiting by LDAP servers
+
+const groupOptionUTC = 0x1 // msDFSR-Options flag indicating that the group's schedules are in UTC
+
+const connectionOptionNoCrossFileRDC = 0x1 // msDFSR-Options flag indicating that cross-file RDC is disabled on a connection
+
+const sysvolGroupName = "Domain System Volume" // Name of the replication group that replicates SYSVOL
+
+const sysvolStateShift = 4 // Position of the SYSVOL migration state within msDFSR-Flags
+
+const fileTimeEpoch = 116444736000000000 // Windows file time of the Unix epoch, in 100 nanosecond intervals since 1601
+
+// userAccountControl flags of computer accounts
+const (
+	uacAccountDisable        = 0x2        // The account is disabled
+	uacServerTrustAccount    = 0x2000     // Writable domain controller
+	uacPartialSecretsAccount = 0x04000000 // Read-only domain controller
+)
+
+// groupAttributeNames are the attributes of msDFSR-ReplicationGroup objects
+// that are retrieved by subtree searches.
+var groupAttributeNames = []string{
+	"description",
+	"msDFSR-ReplicationGroupType",
+	"msDFSR-Version",
+	"msDFSR-Flags",
+	"msDFSR-Options",
+	"msDFSR-Schedule",
+}
+
+// connectionAttributeNames are the attributes of msDFSR-Connection objects
+// that are retrieved by subtree searches.
+var connectionAttributeNames = []string{
+	"fromServer",
+	"msDFSR-Enabled",
+	"msDFSR-Schedule",
+	"msDFSR-RdcEnabled",
+	"msDFSR-Keywords",
+	"msDFSR-Options",
+}
+
+// computerAttributeNames are the attributes of computer objects that are
+// retrieved by subtree searches.
+var computerAttributeNames = []string{
+	"dNSHostName",
+	"operatingSystem",
+	"operatingSystemVersion",
+	"lastLogonTimestamp",
+	"userAccountControl",
+}
+
+// subscriptionAttributeNames are the attributes of msDFSR-Subscription objects
+// that are retrieved by subtree searches.
+var subscriptionAttributeNames = []string{
+	"msDFSR-ContentSetGuid",
+	"msDFSR-RootPath",
+	"msDFSR-StagingPath",
+	"msDFSR-StagingSize",
+	"msDFSR-ConflictPath",
+	"msDFSR-ConflictSize",
+	"msDFSR-ReadOnly",
+	"msDFSR-Enabled",
+}
+
+var (
+	// ErrSearchUnsupported is returned when ModeSearch is requested for a
+	// directory that does not implement directory.Searcher.
+	ErrSearchUnsupported = errors.New("The directory does not support subtree searches.")
+
+	// ErrInvalidMode is returned when a retrieval mode is not recognized.
+	ErrInvalidMode = errors.New("The configuration retrieval mode is invalid.")
+)
@@ -1,21 +1,26 @@
 package globalsettings
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 
 	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
 	"gopkg.in/dfsr.v0/config/membercache"
 	"gopkg.in/dfsr.v0/core"
+
+	"github.com/go-ole/go-ole"
 )
 
 // GlobalSettings provides a means of querying DFSR global settings.
 type GlobalSettings struct {
-	client   *adsi.Client
+	dir      directory.Directory
 	domainDN string
 	mc       *membercache.Cache // Maps distinguished names to MemberInfo
+	mode     Mode
 }
 
 // New returns a new DFSR global settings configuration manager for the given
@@ -26,38 +31,74 @@ type GlobalSettings struct {
 // responsibility to explicitly close the ADSI client at an appropriate time
 // when finished with the global settings.
 func New(client *adsi.Client, domain string) *GlobalSettings {
+	return NewWithDirectory(adsidir.New(client), domain)
+}
+
+// NewWithDirectory returns a new DFSR global settings configuration manager
+// for the given domain that performs its queries against the provided
+// directory.
+//
+// The directory is retained by the global settings. It is the caller's
+// responsibility to close the directory at an appropriate time when finished
+// with the global settings.
+func NewWithDirectory(dir directory.Directory, domain string) *GlobalSettings {
+	return NewWithConfig(dir, domain, Config{})
+}
+
+// NewWithConfig returns a new DFSR global settings configuration manager for
+// the given domain that performs its queries against the provided directory
+// with the given configuration.
+//
+// The directory is retained by the global settings. It is the caller's
+// responsibility to close the directory at an appropriate time when finished
+// with the global settings.
+func NewWithConfig(dir directory.Directory, domain string, config Config) *GlobalSettings {
 	return &GlobalSettings{
-		client:   client,
+		dir:      dir,
 		domainDN: domainDN(domain),
 		mc:       membercache.New(),
+		mode:     config.Mode,
 	}
 }
 
-// Domain will fetch DFSR configuration data from the domain.
-func (gs *GlobalSettings) Domain() (domain core.Domain, err error) {
+// Domain will fetch DFSR configuration data from the domain, including the
+// SYSVOL migration state and its domain controllers. If the SYSVOL state cannot
+// be retrieved the groups are returned without it and the error is recorded in
+// the SysvolErr field of the domain.
+func (gs *GlobalSettings) Domain(ctx context.Context) (domain core.Domain, err error) {
 	start := time.Now()
 
-	nc, err := gs.NamingContext()
+	nc, err := gs.NamingContext(ctx)
 	if err != nil {
 		return
 	}
 
-	groups, err := gs.Groups()
+	groups, computerObjects, err := gs.groups(ctx)
 	if err != nil {
 		return
 	}
 
+	sysvol, sysvolErr := gs.sysvol(ctx, computerObjects)
+	if sysvolErr != nil {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+	}
+	assignSysvolMembers(sysvol, groups)
+
 	return core.Domain{
 		NamingContext:  nc,
 		Groups:         groups,
+		Sysvol:         sysvol,
+		SysvolErr:      sysvolErr,
 		ConfigDuration: time.Now().Sub(start),
 	}, nil
 }
 
 // NamingContext returns information about the default naming context for the
 // domain.
-func (gs *GlobalSettings) NamingContext() (nc core.NamingContext, err error) {
-	domain, err := gs.client.Open(ldap(gs.domainDN))
+func (gs *GlobalSettings) NamingContext(ctx context.Context) (nc core.NamingContext, err error) {
+	domain, err := gs.dir.Open(gs.domainDN)
 	if err != nil {
 		return
 	}
@@ -68,12 +109,12 @@ func (gs *GlobalSettings) NamingContext() (nc core.NamingContext, err error) {
 		return
 	}
 
-	nc.Path, err = domain.Path()
+	nc.DN, err = domain.DN()
 	if err != nil {
 		return
 	}
 
-	nc.DN = strings.TrimPrefix(nc.Path, "LDAP://")
+	nc.Path = ldap(nc.DN)
 
 	nc.Description, err = domain.AttrString("description")
 	if err != nil {
@@ -84,7 +125,42 @@ func (gs *GlobalSettings) NamingContext() (nc core.NamingContext, err error) {
 
 // Groups retreives the DFSR group configuration for all groups contained in the
 // domain.
-func (gs *GlobalSettings) Groups() (groups []core.Group, err error) {
+//
+// A failure to retrieve the configuration of an individual group does not
+// cause Groups to fail. The group is returned with its Err field set instead,
+// so that one inaccessible group does not hide the others. An error is only
+// returned when the set of groups itself could not be retrieved.
+func (gs *GlobalSettings) Groups(ctx context.Context) (groups []core.Group, err error) {
+	groups, _, err = gs.groups(ctx)
+	return
+}
+
+// groups retrieves the DFSR group configuration for all groups. If it
+// performed subtree searches it also returns the computer objects of the
+// domain, otherwise computerObjects is nil.
+func (gs *GlobalSettings) groups(ctx context.Context) (groups []core.Group, computerObjects []directory.Object, err error) {
+	searcher, ok := gs.dir.(directory.Searcher)
+	switch gs.mode {
+	case ModeObjects:
+		groups, err = gs.openGroups(ctx)
+		return
+	case ModeSearch:
+		if !ok {
+			return nil, nil, ErrSearchUnsupported
+		}
+		return gs.search(ctx, searcher)
+	default:
+		if ok {
+			return gs.search(ctx, searcher)
+		}
+		groups, err = gs.openGroups(ctx)
+		return
+	}
+}
+
+// openGroups retrieves the DFSR group configuration for all groups by opening
+// each object individually.
+func (gs *GlobalSettings) openGroups(ctx context.Context) (groups []core.Group, err error) {
 	container, err := gs.openContainer(makeDN("cn", "DFSR-GlobalSettings", "System"))
 	if err != nil {
 		return nil, err
@@ -99,32 +175,51 @@ func (gs *GlobalSettings) Groups() (groups []core.Group, err error) {
 
 	var results []chan groupResult
 
-	for g, gerr := iter.Next(); gerr == nil; g, gerr = iter.Next() {
+	for {
+		g, gerr := iter.Next()
+		if errors.Is(gerr, directory.ErrDone) {
+			break
+		}
+		if gerr != nil {
+			err = gerr // Wait for the groups already being retrieved
+			break
+		}
+		if ctx.Err() != nil {
+			g.Close()
+			break
+		}
+
 		ch := make(chan groupResult, 1)
 		results = append(results, ch)
 
-		go func(ch chan groupResult, g *adsi.Object) {
+		go func(ch chan groupResult, g directory.Object) {
 			defer g.Close()
 			defer close(ch)
-			group, werr := gs.group(g)
+			group, werr := gs.group(ctx, g)
 			ch <- groupResult{Group: group, Err: werr}
 		}(ch, g)
 
-		time.Sleep(groupQueryDelay) // Try to avoid rate-limiting
+		// Try to avoid rate-limiting
+		select {
+		case <-ctx.Done():
+		case <-time.After(groupQueryDelay):
+		}
 	}
 
 	for i := 0; i < len(results); i++ {
 		result := <-results[i]
-		if err != nil {
-			continue // Already hit an error, just drain the channels
-		}
-
 		if result.Err != nil {
-			err = fmt.Errorf("Error retrieving configuration for replication group %v: %v", i, result.Err.Error())
-			groups = nil
-		} else {
-			groups = append(groups, result.Group)
+			result.Group = failedGroup(result.Group, result.Err)
 		}
+		groups = append(groups, result.Group)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Failures caused by cancellation are not specific to any one group
+	if err = ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	return
@@ -160,7 +255,7 @@ func (gs *GlobalSettings) groups() (groups []core.Group, err error) {
 */
 
 // GroupByName retreives the DFSR group configuration for the given name.
-func (gs *GlobalSettings) GroupByName(groupName string) (group core.Group, err error) {
+func (gs *GlobalSettings) GroupByName(ctx context.Context, groupName string) (group core.Group, err error) {
 	groupName = strings.ToLower(groupName)
 
 	container, err := gs.openContainer(makeDN("cn", "DFSR-GlobalSettings", "System"))
@@ -175,18 +270,29 @@ func (gs *GlobalSettings) GroupByName(groupName string) (group core.Group, err e
 	}
 	defer iter.Close()
 
-	for g, gerr := iter.Next(); gerr == nil; g, gerr = iter.Next() {
+	for {
+		g, gerr := iter.Next()
+		if errors.Is(gerr, directory.ErrDone) {
+			break
+		}
+		if gerr != nil {
+			return core.Group{}, gerr
+		}
 		defer g.Close()
 
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
 		candidate, cerr := g.Name()
-		if err != nil {
+		if cerr != nil {
 			err = cerr
 			return
 		}
 		candidate = strings.ToLower(candidate)
 
 		if candidate == groupName || strings.TrimPrefix(candidate, "cn=") == groupName {
-			return gs.group(g)
+			return gs.group(ctx, g)
 		}
 	}
 
@@ -196,19 +302,23 @@ func (gs *GlobalSettings) GroupByName(groupName string) (group core.Group, err e
 
 // Group retreives the DFSR group configuration for the given distinguished
 // name.
-func (gs *GlobalSettings) Group(groupDN string) (group core.Group, err error) {
-	g, err := gs.client.Open(ldap(groupDN))
+func (gs *GlobalSettings) Group(ctx context.Context, groupDN string) (group core.Group, err error) {
+	g, err := gs.dir.Open(groupDN)
 	if err != nil {
 		return
 	}
 	defer g.Close()
 
-	return gs.group(g)
+	return gs.group(ctx, g)
 }
 
-func (gs *GlobalSettings) group(g *adsi.Object) (group core.Group, err error) {
+func (gs *GlobalSettings) group(ctx context.Context, g directory.Object) (group core.Group, err error) {
 	start := time.Now()
 
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
 	group.Name, err = g.Name()
 	if err != nil {
 		return
@@ -220,49 +330,74 @@ func (gs *GlobalSettings) group(g *adsi.Object) (group core.Group, err error) {
 		return
 	}
 
-	gc, err := g.ToContainer()
+	group.Schedule, err = schedule(g)
 	if err != nil {
 		return
 	}
-	defer gc.Close()
 
-	content, err := gc.Container("msDFSR-Content", "cn=Content")
+	err = groupAttributes(g, &group)
+	if err != nil {
+		return
+	}
+
+	content, err := g.Child("msDFSR-Content", "cn=Content")
 	if err != nil {
 		return
 	}
 	defer content.Close()
 
-	group.Folders, err = gs.folders(content)
+	group.Folders, err = gs.folders(ctx, content)
 	if err != nil {
 		return
 	}
 
-	topology, err := gc.Object("msDFSR-Topology", "cn=Topology")
+	topology, err := g.Child("msDFSR-Topology", "cn=Topology")
 	if err != nil {
 		return
 	}
 	defer topology.Close()
 
-	group.Members, err = gs.members(topology)
+	group.Members, err = gs.members(ctx, topology)
 	if err != nil {
 		return
 	}
 
+	for m := range group.Members {
+		member := &group.Members[m]
+		member.Subscriptions, err = gs.subscriptions(ctx, member, group.Folders)
+		if err != nil {
+			return
+		}
+	}
+
+	setScheduleZone(&group)
+
 	group.ConfigDuration = time.Now().Sub(start)
 
 	return
 }
 
-func (gs *GlobalSettings) folders(content *adsi.Container) (folders []core.Folder, err error) {
+func (gs *GlobalSettings) folders(ctx context.Context, content directory.Object) (folders []core.Folder, err error) {
 	iter, err := content.Children()
 	if err != nil {
 		return nil, err
 	}
 	defer iter.Close()
 
-	for f, err := iter.Next(); err == nil; f, err = iter.Next() {
+	for {
+		f, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 		defer f.Close()
 
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		folder, err := gs.folder(f)
 		if err != nil {
 			return nil, err
@@ -274,7 +409,7 @@ func (gs *GlobalSettings) folders(content *adsi.Container) (folders []core.Folde
 	return
 }
 
-func (gs *GlobalSettings) folder(f *adsi.Object) (folder core.Folder, err error) {
+func (gs *GlobalSettings) folder(f directory.Object) (folder core.Folder, err error) {
 	folder.Name, err = f.Name()
 	if err != nil {
 		return
@@ -286,26 +421,53 @@ func (gs *GlobalSettings) folder(f *adsi.Object) (folder core.Folder, err error)
 		return
 	}
 
-	return
-}
+	folder.Description, err = f.AttrString("description")
+	if err != nil {
+		return
+	}
 
-func (gs *GlobalSettings) members(topology *adsi.Object) (members []core.Member, err error) {
-	tc, err := topology.ToContainer()
+	fileFilter, err := f.AttrString("msDFSR-FileFilter")
 	if err != nil {
-		return nil, err
+		return
 	}
-	defer tc.Close()
+	folder.FileFilter = core.Filter(fileFilter)
+
+	directoryFilter, err := f.AttrString("msDFSR-DirectoryFilter")
+	if err != nil {
+		return
+	}
+	folder.DirectoryFilter = core.Filter(directoryFilter)
+
+	folder.DfsPath, err = f.AttrString("msDFSR-DfsPath")
+	if err != nil {
+		return
+	}
+
+	return
+}
 
-	iter, err := tc.Children()
+func (gs *GlobalSettings) members(ctx context.Context, topology directory.Object) (members []core.Member, err error) {
+	iter, err := topology.Children()
 	if err != nil {
 		return nil, err
 	}
 	defer iter.Close()
 
-	for m, err := iter.Next(); err == nil; m, err = iter.Next() {
+	for {
+		m, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 		defer m.Close()
 
-		member, err := gs.member(m, "")
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		member, err := gs.member(ctx, m, "")
 		if err != nil {
 			return nil, err
 		}
@@ -318,18 +480,18 @@ func (gs *GlobalSettings) members(topology *adsi.Object) (members []core.Member,
 
 // Member retreives the DFSR member configuration for the given distinguished
 // name. The member's connection list is included in the returned data.
-func (gs *GlobalSettings) Member(memberDN string) (member core.Member, err error) {
-	m, err := gs.client.Open(ldap(memberDN))
+func (gs *GlobalSettings) Member(ctx context.Context, memberDN string) (member core.Member, err error) {
+	m, err := gs.dir.Open(memberDN)
 	if err != nil {
 		return
 	}
 	defer m.Close()
 
-	return gs.member(m, memberDN)
+	return gs.member(ctx, m, memberDN)
 }
 
-func (gs *GlobalSettings) member(m *adsi.Object, dn string) (member core.Member, err error) {
-	member.MemberInfo, err = gs.memberInfo(m, dn)
+func (gs *GlobalSettings) member(ctx context.Context, m directory.Object, dn string) (member core.Member, err error) {
+	member.MemberInfo, err = gs.memberInfo(ctx, m, dn)
 	if err != nil {
 		return
 	}
@@ -337,46 +499,45 @@ func (gs *GlobalSettings) member(m *adsi.Object, dn string) (member core.Member,
 	serverref, _ := m.AttrString("serverReference")
 	if serverref == "" {
 		// Standard DFSR membership
-		member.Connections, err = gs.connections(m)
+		member.Connections, err = gs.connections(ctx, m)
 		return
 	}
 
 	// Domain System Volume membership
-	server, err := gs.client.Open(ldap(serverref))
+	server, err := gs.dir.Open(serverref)
 	if err != nil {
 		return
 	}
+	defer server.Close()
 
-	member.Connections, err = gs.connections(server)
+	member.Connections, err = gs.connections(ctx, server)
 	return
 }
 
 // MemberInfo retreives the DFSR member configuration for the given
 // distinguished name. The member's connection list is not included in the
 // returned data.
-func (gs *GlobalSettings) MemberInfo(memberDN string) (member core.MemberInfo, err error) {
+func (gs *GlobalSettings) MemberInfo(ctx context.Context, memberDN string) (member core.MemberInfo, err error) {
 	member, ok := gs.mc.Retrieve(memberDN)
 	if ok {
 		return
 	}
 
-	m, err := gs.client.Open(ldap(memberDN))
+	m, err := gs.dir.Open(memberDN)
 	if err != nil {
 		return
 	}
 	defer m.Close()
 
-	return gs.memberInfo(m, memberDN)
+	return gs.memberInfo(ctx, m, memberDN)
 }
 
-func (gs *GlobalSettings) memberInfo(m *adsi.Object, dn string) (member core.MemberInfo, err error) {
+func (gs *GlobalSettings) memberInfo(ctx context.Context, m directory.Object, dn string) (member core.MemberInfo, err error) {
 	if dn == "" {
-		path, perr := m.Path()
+		member.DN, err = m.DN()
 		if err != nil {
-			err = perr
 			return
 		}
-		member.DN = strings.TrimPrefix(path, "LDAP://")
 	} else {
 		member.DN = dn
 	}
@@ -388,8 +549,13 @@ func (gs *GlobalSettings) memberInfo(m *adsi.Object, dn string) (member core.Mem
 
 	var compref string
 	switch class {
-	case "nTDSDSA":
+	case "nTDSDSA", "nTDSDSARO":
+		// Domain System Volume membership of a domain controller or a
+		// read-only domain controller
 		m, err = gs.openParent(m)
+		if err != nil {
+			return
+		}
 		defer m.Close()
 		fallthrough
 	case "server":
@@ -418,29 +584,34 @@ func (gs *GlobalSettings) memberInfo(m *adsi.Object, dn string) (member core.Mem
 		return
 	}
 
-	member.Computer, err = gs.Computer(compref)
+	member.Computer, err = gs.Computer(ctx, compref)
 
 	gs.mc.Set(member) // Add member info to the cache
 	return
 }
 
-func (gs *GlobalSettings) connections(member *adsi.Object) (connections []core.Connection, err error) {
-	mc, err := member.ToContainer()
-	if err != nil {
-		return nil, err
-	}
-	defer mc.Close()
-
-	iter, err := mc.Children()
+func (gs *GlobalSettings) connections(ctx context.Context, member directory.Object) (connections []core.Connection, err error) {
+	iter, err := member.Children()
 	if err != nil {
 		return nil, err
 	}
 	defer iter.Close()
 
-	for c, err := iter.Next(); err == nil; c, err = iter.Next() {
+	for {
+		c, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 		defer c.Close()
 
-		conn, err := gs.connection(c)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		conn, err := gs.connection(ctx, c)
 		if err != nil {
 			return nil, err
 		}
@@ -451,7 +622,7 @@ func (gs *GlobalSettings) connections(member *adsi.Object) (connections []core.C
 	return
 }
 
-func (gs *GlobalSettings) connection(c *adsi.Object) (conn core.Connection, err error) {
+func (gs *GlobalSettings) connection(ctx context.Context, c directory.Object) (conn core.Connection, err error) {
 	class, err := c.Class()
 	if err != nil {
 		return
@@ -479,12 +650,29 @@ func (gs *GlobalSettings) connection(c *adsi.Object) (conn core.Connection, err
 		if err != nil {
 			return
 		}
+
+		conn.Schedule, err = schedule(c)
+		if err != nil {
+			return
+		}
+
+		err = connectionAttributes(c, &conn)
+		if err != nil {
+			return
+		}
 	} else if class == "nTDSConnection" {
 		// Domain System Volume membership
 		conn.Enabled = true // These members are always enabled
+		conn.RDC = true     // These connections use the DFSR defaults
+		conn.CrossFileRDC = true
 	}
 
-	mi, err := gs.MemberInfo(conn.MemberDN)
+	// A source that no longer exists leaves the computer of the connection
+	// empty instead of failing the group
+	mi, err := gs.MemberInfo(ctx, conn.MemberDN)
+	if errors.Is(err, directory.ErrNotFound) {
+		return conn, nil
+	}
 	if err != nil {
 		return
 	}
@@ -494,9 +682,141 @@ func (gs *GlobalSettings) connection(c *adsi.Object) (conn core.Connection, err
 	return
 }
 
-// Computer retrieves the DNS host name for the given distinguished name.
-func (gs *GlobalSettings) Computer(dn string) (computer core.Computer, err error) {
-	c, err := gs.client.Open(ldap(dn))
+// subscriptions retrieves the local settings of the member's replicated
+// folders from the DFSR-LocalSettings container of its computer object. The
+// folders of the member's group are used to identify each subscription.
+//
+// A computer without local settings has not been configured yet, so no
+// subscriptions are returned for it.
+func (gs *GlobalSettings) subscriptions(ctx context.Context, member *core.Member, folders []core.Folder) (subscriptions []core.Subscription, err error) {
+	if member.Computer.DN == "" {
+		return
+	}
+
+	settings, err := gs.dir.Open(combineDN("CN=DFSR-LocalSettings", member.Computer.DN))
+	if errors.Is(err, directory.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+	defer settings.Close()
+
+	iter, err := settings.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for {
+		s, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ref, err := s.AttrString("msDFSR-MemberReference")
+		if err != nil {
+			return nil, err
+		}
+		if dnKey(ref) != dnKey(member.DN) {
+			continue // Subscriber of another group
+		}
+
+		return gs.subscriber(ctx, s, folders)
+	}
+
+	return
+}
+
+// subscriber retrieves the subscriptions beneath a subscriber object.
+func (gs *GlobalSettings) subscriber(ctx context.Context, subscriber directory.Object, folders []core.Folder) (subscriptions []core.Subscription, err error) {
+	iter, err := subscriber.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for {
+		s, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		subscription, err := gs.subscription(s, folders)
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return
+}
+
+func (gs *GlobalSettings) subscription(s directory.Object, folders []core.Folder) (subscription core.Subscription, err error) {
+	subscription.DN, err = s.DN()
+	if err != nil {
+		return
+	}
+
+	id, err := s.AttrBytes("msDFSR-ContentSetGuid")
+	if err != nil {
+		return
+	}
+	if len(id) > 0 {
+		if subscription.Folder.ID, err = directory.ParseGUID(id); err != nil {
+			return
+		}
+		for f := range folders {
+			if folders[f].ID != nil && ole.IsEqualGUID(folders[f].ID, subscription.Folder.ID) {
+				subscription.Folder = folders[f]
+				break
+			}
+		}
+	}
+
+	if subscription.RootPath, err = s.AttrString("msDFSR-RootPath"); err != nil {
+		return
+	}
+	if subscription.StagingPath, err = s.AttrString("msDFSR-StagingPath"); err != nil {
+		return
+	}
+	if subscription.StagingQuota, err = optionalInt(s, "msDFSR-StagingSize"); err != nil {
+		return
+	}
+	if subscription.ConflictPath, err = s.AttrString("msDFSR-ConflictPath"); err != nil {
+		return
+	}
+	if subscription.ConflictQuota, err = optionalInt(s, "msDFSR-ConflictSize"); err != nil {
+		return
+	}
+	if subscription.ReadOnly, err = optionalBool(s, "msDFSR-ReadOnly", false); err != nil {
+		return
+	}
+	subscription.Enabled, err = optionalBool(s, "msDFSR-Enabled", false)
+	return
+}
+
+// Computer retrieves the DNS host name, operating system, last logon time and
+// account state of the computer with the given distinguished name.
+func (gs *GlobalSettings) Computer(ctx context.Context, dn string) (computer core.Computer, err error) {
+	c, err := gs.dir.Open(dn)
 	if err != nil {
 		return
 	}
@@ -505,31 +825,52 @@ func (gs *GlobalSettings) Computer(dn string) (computer core.Computer, err error
 	return gs.computer(c)
 }
 
-func (gs *GlobalSettings) computer(c *adsi.Object) (computer core.Computer, err error) {
-	computer.DN, err = c.Path()
+func (gs *GlobalSettings) computer(c directory.Object) (computer core.Computer, err error) {
+	computer.DN, err = c.DN()
 	if err != nil {
 		return
 	}
-	computer.DN = strings.TrimPrefix(computer.DN, "LDAP://")
 
 	computer.Host, err = c.AttrString("dNSHostName")
 	if err != nil {
 		return
 	}
 
+	computer.OperatingSystem, err = c.AttrString("operatingSystem")
+	if err != nil {
+		return
+	}
+
+	computer.OperatingSystemVersion, err = c.AttrString("operatingSystemVersion")
+	if err != nil {
+		return
+	}
+
+	lastLogon, err := optionalInt64(c, "lastLogonTimestamp")
+	if err != nil {
+		return
+	}
+	computer.LastLogon = fileTime(lastLogon)
+
+	uac, err := optionalInt(c, "userAccountControl")
+	if err != nil {
+		return
+	}
+	computer.Disabled = uac&uacAccountDisable != 0
+	computer.DomainController = uac&(uacServerTrustAccount|uacPartialSecretsAccount) != 0
+
 	return
 }
 
-func (gs *GlobalSettings) openParent(o *adsi.Object) (parent *adsi.Object, err error) {
-	path, err := o.Parent()
+func (gs *GlobalSettings) openParent(o directory.Object) (parent directory.Object, err error) {
+	dn, err := o.Parent()
 	if err != nil {
 		return
 	}
 
-	return gs.client.Open(path)
+	return gs.dir.Open(dn)
 }
 
-func (gs *GlobalSettings) openContainer(partialDN string) (*adsi.Container, error) {
-	path := ldap(combineDN(partialDN, gs.domainDN))
-	return gs.client.OpenContainer(path)
+func (gs *GlobalSettings) openContainer(partialDN string) (directory.Object, error) {
+	return gs.dir.Open(combineDN(partialDN, gs.domainDN))
 }
@@ -0,0 +1,327 @@
+package globalsettings
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// memberRef locates a member within a slice of groups.
+type memberRef struct {
+	group  int
+	member int
+}
+
+// search retrieves the DFSR group configuration for all groups with a small
+// number of paged subtree searches and assembles it in memory.
+//
+// Members of the Domain System Volume group refer to their connections through
+// a server object in the configuration partition. Their connections are
+// retrieved individually.
+//
+// Because all groups are retrieved together, the configuration duration of
+// each group is the time taken to retrieve all of them. Errors encountered
+// while assembling a group are recorded on that group.
+//
+// The computer objects of the domain found by the search are returned as well,
+// so that they can be reused by the retrieval of the SYSVOL state.
+func (gs *GlobalSettings) search(ctx context.Context, s directory.Searcher) (groups []core.Group, computerObjects []directory.Object, err error) {
+	start := time.Now()
+
+	base := combineDN(makeDN("cn", "DFSR-GlobalSettings", "System"), gs.domainDN)
+
+	groupObjects, err := s.Search(ctx, base, "msDFSR-ReplicationGroup", groupAttributeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	folderObjects, err := s.Search(ctx, base, "msDFSR-ContentSet", []string{"description", "msDFSR-FileFilter", "msDFSR-DirectoryFilter", "msDFSR-DfsPath"})
+	if err != nil {
+		return nil, nil, err
+	}
+	memberObjects, err := s.Search(ctx, base, "msDFSR-Member", []string{"msDFSR-ComputerReference", "serverReference"})
+	if err != nil {
+		return nil, nil, err
+	}
+	connectionObjects, err := s.Search(ctx, base, "msDFSR-Connection", connectionAttributeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	computerObjects, err = s.Search(ctx, gs.domainDN, "computer", computerAttributeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	subscriberObjects, err := s.Search(ctx, gs.domainDN, "msDFSR-Subscriber", []string{"msDFSR-MemberReference"})
+	if err != nil {
+		return nil, nil, err
+	}
+	subscriptionObjects, err := s.Search(ctx, gs.domainDN, "msDFSR-Subscription", subscriptionAttributeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fail records the first error encountered for a group
+	fail := func(g int, err error) {
+		if groups[g].Err == nil {
+			groups[g].Err = err
+		}
+	}
+
+	// Groups
+	groupIndex := make(map[string]int, len(groupObjects))
+	for _, g := range groupObjects {
+		dn, derr := g.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		rdn, _ := directory.SplitDN(dn)
+		index := len(groups)
+		groupIndex[dnKey(dn)] = index
+		groups = append(groups, core.Group{Name: strings.TrimPrefix(rdn, "CN=")})
+		group := &groups[index]
+		var gerr error
+		if group.ID, gerr = g.GUID(); gerr != nil {
+			fail(index, gerr)
+		}
+		if group.Schedule, gerr = schedule(g); gerr != nil {
+			fail(index, gerr)
+		}
+		if gerr = groupAttributes(g, group); gerr != nil {
+			fail(index, gerr)
+		}
+	}
+
+	// Folders are stored in CN=<folder>,CN=Content,<group>
+	for _, f := range folderObjects {
+		dn, derr := f.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		g, ok := groupIndex[dnKey(ancestor(dn, 2))]
+		if !ok {
+			continue
+		}
+		folder, ferr := gs.folder(f)
+		if ferr != nil {
+			fail(g, ferr)
+			continue
+		}
+		groups[g].Folders = append(groups[g].Folders, folder)
+	}
+
+	// Computers that cannot be parsed only fail the groups whose members
+	// refer to them
+	computers := make(map[string]core.Computer, len(computerObjects))
+	computerErrs := make(map[string]error)
+	for _, c := range computerObjects {
+		dn, derr := c.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		computer, cerr := gs.computer(c)
+		if cerr != nil {
+			computerErrs[dnKey(dn)] = cerr
+			continue
+		}
+		computers[dnKey(computer.DN)] = computer
+	}
+
+	// Members are stored in CN=<member>,CN=Topology,<group>
+	members := make(map[string]memberRef, len(memberObjects))
+	for _, m := range memberObjects {
+		dn, derr := m.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		g, ok := groupIndex[dnKey(ancestor(dn, 2))]
+		if !ok {
+			continue
+		}
+
+		member, merr := gs.searchMember(ctx, m, dn, computers, computerErrs)
+		if merr != nil {
+			fail(g, merr)
+			continue
+		}
+
+		members[dnKey(dn)] = memberRef{group: g, member: len(groups[g].Members)}
+		groups[g].Members = append(groups[g].Members, member)
+	}
+
+	// Connections are stored in CN=<connection>,<member>
+	for _, c := range connectionObjects {
+		dn, derr := c.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		ref, ok := members[dnKey(ancestor(dn, 1))]
+		if !ok {
+			continue
+		}
+
+		conn, cerr := gs.searchConnection(c)
+		if cerr != nil {
+			fail(ref.group, cerr)
+			continue
+		}
+
+		if source, found := members[dnKey(conn.MemberDN)]; found {
+			conn.Computer = groups[source.group].Members[source.member].Computer
+		} else {
+			// A source that no longer exists leaves the computer of the
+			// connection empty instead of failing the group
+			mi, merr := gs.MemberInfo(ctx, conn.MemberDN)
+			if merr != nil && !errors.Is(merr, directory.ErrNotFound) {
+				fail(ref.group, merr)
+				continue
+			}
+			conn.Computer = mi.Computer
+		}
+
+		member := &groups[ref.group].Members[ref.member]
+		member.Connections = append(member.Connections, conn)
+	}
+
+	// Subscribers are stored in CN=<subscriber>,CN=DFSR-LocalSettings,<computer>
+	// and refer to the member they belong to
+	subscribers := make(map[string]memberRef, len(subscriberObjects))
+	for _, o := range subscriberObjects {
+		dn, derr := o.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		ref, rerr := o.AttrString("msDFSR-MemberReference")
+		if rerr != nil {
+			return nil, nil, rerr
+		}
+		if member, found := members[dnKey(ref)]; found {
+			subscribers[dnKey(dn)] = member
+		}
+	}
+
+	// Subscriptions are stored in CN=<subscription>,<subscriber>
+	for _, o := range subscriptionObjects {
+		dn, derr := o.DN()
+		if derr != nil {
+			return nil, nil, derr
+		}
+		ref, ok := subscribers[dnKey(ancestor(dn, 1))]
+		if !ok {
+			continue
+		}
+
+		subscription, serr := gs.subscription(o, groups[ref.group].Folders)
+		if serr != nil {
+			fail(ref.group, serr)
+			continue
+		}
+
+		member := &groups[ref.group].Members[ref.member]
+		member.Subscriptions = append(member.Subscriptions, subscription)
+	}
+
+	// Failures caused by cancellation are not specific to any one group
+	if err = ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	duration := time.Now().Sub(start)
+	for g := range groups {
+		if groups[g].Err != nil {
+			groups[g] = failedGroup(groups[g], groups[g].Err)
+			continue
+		}
+		setScheduleZone(&groups[g])
+		groups[g].ConfigDuration = duration
+	}
+
+	return
+}
+
+// searchMember assembles a member from a search result. The member's
+// computer is taken from the given set of computers when present. If the
+// computer was found but could not be parsed its error is returned.
+func (gs *GlobalSettings) searchMember(ctx context.Context, m directory.Object, dn string, computers map[string]core.Computer, computerErrs map[string]error) (member core.Member, err error) {
+	member.DN = dn
+
+	member.Name, err = m.Name()
+	if err != nil {
+		return
+	}
+	member.Name = strings.TrimPrefix(member.Name, "CN=")
+
+	member.ID, err = m.GUID()
+	if err != nil {
+		return
+	}
+
+	compref, err := m.AttrString("msDFSR-ComputerReference")
+	if err != nil {
+		return
+	}
+	if computer, found := computers[dnKey(compref)]; found {
+		member.Computer = computer
+	} else if cerr, found := computerErrs[dnKey(compref)]; found {
+		err = cerr
+		return
+	} else if compref != "" {
+		// The computer might belong to another domain
+		member.Computer, err = gs.Computer(ctx, compref)
+		if err != nil {
+			return
+		}
+	}
+
+	gs.mc.Set(member.MemberInfo) // Add member info to the cache
+
+	serverref, _ := m.AttrString("serverReference")
+	if serverref == "" {
+		return
+	}
+
+	// Domain System Volume membership
+	server, err := gs.dir.Open(serverref)
+	if err != nil {
+		return
+	}
+	defer server.Close()
+
+	member.Connections, err = gs.connections(ctx, server)
+	return
+}
+
+// searchConnection assembles a connection from a search result. The
+// connection's computer is not populated.
+func (gs *GlobalSettings) searchConnection(c directory.Object) (conn core.Connection, err error) {
+	conn.Name, err = c.Name()
+	if err != nil {
+		return
+	}
+	conn.Name = strings.TrimPrefix(conn.Name, "CN=")
+
+	conn.ID, err = c.GUID()
+	if err != nil {
+		return
+	}
+
+	conn.MemberDN, err = c.AttrString("fromServer")
+	if err != nil {
+		return
+	}
+
+	conn.Enabled, err = c.AttrBool("msDFSR-Enabled")
+	if err != nil {
+		return
+	}
+
+	conn.Schedule, err = schedule(c)
+	if err != nil {
+		return
+	}
+
+	err = connectionAttributes(c, &conn)
+	return
+}
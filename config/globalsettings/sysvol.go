@@ -0,0 +1,210 @@
+package globalsettings
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// Sysvol retrieves the SYSVOL migration state of the domain and its domain
+// controllers, including read-only domain controllers.
+//
+// The global migration state is stored on the DFSR-GlobalSettings container
+// and the local state of each domain controller on the DFSR-LocalSettings
+// object beneath its computer object. A domain controller without local
+// settings has not started the migration. The members of the SYSVOL group
+// are assigned to the domain controllers by Domain.
+func (gs *GlobalSettings) Sysvol(ctx context.Context) (sysvol *core.Sysvol, err error) {
+	return gs.sysvol(ctx, nil)
+}
+
+// sysvol retrieves the SYSVOL migration state. If subtree searches are used
+// and computerObjects is not nil, the domain controllers are taken from
+// computerObjects instead of searching for the computers of the domain again.
+func (gs *GlobalSettings) sysvol(ctx context.Context, computerObjects []directory.Object) (sysvol *core.Sysvol, err error) {
+	container, err := gs.openContainer(makeDN("cn", "DFSR-GlobalSettings", "System"))
+	if err != nil {
+		return nil, err
+	}
+	defer container.Close()
+
+	flags, err := optionalInt(container, "msDFSR-Flags")
+	if err != nil {
+		return nil, err
+	}
+
+	sysvol = &core.Sysvol{State: sysvolState(flags)}
+
+	searcher, ok := gs.dir.(directory.Searcher)
+	switch {
+	case gs.mode == ModeSearch && !ok:
+		return nil, ErrSearchUnsupported
+	case gs.mode != ModeObjects && ok:
+		sysvol.DomainControllers, err = gs.searchDomainControllers(ctx, searcher, computerObjects)
+	default:
+		sysvol.DomainControllers, err = gs.openDomainControllers(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sysvol, nil
+}
+
+// assignSysvolMembers assigns the members of the SYSVOL group among the given
+// groups to the domain controllers of sysvol, matching them by computer.
+func assignSysvolMembers(sysvol *core.Sysvol, groups []core.Group) {
+	if sysvol == nil {
+		return
+	}
+	for g := range groups {
+		if !groups[g].Sysvol() {
+			continue
+		}
+		for m := range groups[g].Members {
+			member := &groups[g].Members[m]
+			if dc := sysvol.DomainController(member.Computer.DN); dc != nil {
+				dc.Member = member.Name
+			}
+		}
+	}
+}
+
+// openDomainControllers retrieves the domain controllers in the Domain
+// Controllers organizational unit by opening each object individually.
+func (gs *GlobalSettings) openDomainControllers(ctx context.Context) (dcs []core.DomainController, err error) {
+	container, err := gs.openContainer("OU=Domain Controllers")
+	if errors.Is(err, directory.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer container.Close()
+
+	iter, err := container.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for {
+		c, err := iter.Next()
+		if errors.Is(err, directory.ErrDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		dc, ok, err := gs.domainController(c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		settings, err := gs.dir.Open(combineDN("CN=DFSR-LocalSettings", dc.Computer.DN))
+		if err == nil {
+			defer settings.Close()
+			var flags int
+			if flags, err = optionalInt(settings, "msDFSR-Flags"); err != nil {
+				return nil, err
+			}
+			dc.State = sysvolState(flags)
+		} else if !errors.Is(err, directory.ErrNotFound) {
+			return nil, err
+		}
+
+		dcs = append(dcs, dc)
+	}
+
+	return
+}
+
+// searchDomainControllers retrieves the domain controllers anywhere in the
+// domain with subtree searches. If computerObjects is nil the computers of the
+// domain are searched for, otherwise they are taken from computerObjects.
+func (gs *GlobalSettings) searchDomainControllers(ctx context.Context, s directory.Searcher, computerObjects []directory.Object) (dcs []core.DomainController, err error) {
+	if computerObjects == nil {
+		computerObjects, err = s.Search(ctx, gs.domainDN, "computer", computerAttributeNames)
+		if err != nil {
+			return nil, err
+		}
+	}
+	settingsObjects, err := s.Search(ctx, gs.domainDN, "msDFSR-LocalSettings", []string{"msDFSR-Flags"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Local settings are stored in CN=DFSR-LocalSettings,<computer>
+	states := make(map[string]core.SysvolState, len(settingsObjects))
+	for _, o := range settingsObjects {
+		dn, derr := o.DN()
+		if derr != nil {
+			return nil, derr
+		}
+		flags, ferr := optionalInt(o, "msDFSR-Flags")
+		if ferr != nil {
+			return nil, ferr
+		}
+		states[dnKey(ancestor(dn, 1))] = sysvolState(flags)
+	}
+
+	for _, c := range computerObjects {
+		dc, ok, cerr := gs.domainController(c)
+		if cerr != nil {
+			return nil, cerr
+		}
+		if !ok {
+			continue
+		}
+		dc.State = states[dnKey(dc.Computer.DN)]
+		dcs = append(dcs, dc)
+	}
+
+	return
+}
+
+// domainController returns the domain controller represented by the computer
+// object c. It returns false if the computer is not a domain controller.
+func (gs *GlobalSettings) domainController(c directory.Object) (dc core.DomainController, ok bool, err error) {
+	class, err := c.Class()
+	if err != nil || class != "computer" {
+		return
+	}
+
+	dc.Computer, err = gs.computer(c)
+	if err != nil || !dc.Computer.DomainController {
+		return
+	}
+
+	uac, err := optionalInt(c, "userAccountControl")
+	if err != nil {
+		return
+	}
+	dc.ReadOnly = uac&uacPartialSecretsAccount != 0
+
+	return dc, true, nil
+}
+
+// sysvolState returns the SYSVOL migration state stored in the given
+// msDFSR-Flags value.
+func sysvolState(flags int) core.SysvolState {
+	return core.SysvolState(flags >> sysvolStateShift)
+}
+
+// isSysvolName reports whether name is the name of the group that replicates
+// SYSVOL.
+func isSysvolName(name string) bool {
+	return strings.EqualFold(name, sysvolGroupName)
+}
@@ -1,8 +1,73 @@
 package globalsettings
 
-import "gopkg.in/dfsr.v0/core"
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+)
 
 type groupResult struct {
 	Group core.Group
 	Err   error
 }
+
+// Mode determines how replication group configuration is retrieved.
+type Mode int
+
+// Retrieval modes.
+const (
+	// ModeAuto uses ModeSearch if the directory supports it and ModeObjects
+	// otherwise.
+	ModeAuto Mode = iota
+
+	// ModeObjects opens every group, folder, member, connection and computer
+	// object individually.
+	ModeObjects
+
+	// ModeSearch retrieves all groups, folders, members, connections and
+	// computers with a handful of paged subtree searches and assembles the
+	// configuration in memory. It requires a directory that implements
+	// directory.Searcher.
+	ModeSearch
+)
+
+// String returns a string representation of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeAuto:
+		return "auto"
+	case ModeObjects:
+		return "objects"
+	case ModeSearch:
+		return "search"
+	default:
+		return fmt.Sprintf("mode(%d)", int(m))
+	}
+}
+
+// MarshalText returns the string representation of the mode.
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText parses a mode from its string representation. An empty
+// string is parsed as ModeAuto.
+func (m *Mode) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*m = ModeAuto
+		return nil
+	}
+	for _, candidate := range []Mode{ModeAuto, ModeObjects, ModeSearch} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*m = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: \"%s\"", ErrInvalidMode, text)
+}
+
+// Config holds configuration settings for global settings queries.
+type Config struct {
+	Mode Mode
+}
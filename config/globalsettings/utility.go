@@ -1,6 +1,14 @@
 package globalsettings
 
-import "strings"
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/core"
+)
 
 func ldap(dn string) string {
 	return "LDAP://" + dn
@@ -26,3 +34,145 @@ func makeDN(attribute string, components ...string) string {
 func combineDN(components ...string) string {
 	return strings.Join(components, ",")
 }
+
+// dnKey returns a normalized form of a distinguished name that is suitable
+// for use as a map key.
+func dnKey(dn string) string {
+	var parts []string
+	for dn != "" {
+		var rdn string
+		rdn, dn = directory.SplitDN(dn)
+		parts = append(parts, strings.ToLower(rdn))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ancestor returns the distinguished name of the ancestor of dn that is the
+// given number of levels above it.
+func ancestor(dn string, levels int) string {
+	for i := 0; i < levels; i++ {
+		_, dn = directory.SplitDN(dn)
+	}
+	return dn
+}
+
+// failedGroup returns a copy of group that retains only its identity and
+// records the given error.
+func failedGroup(group core.Group, err error) core.Group {
+	return core.Group{
+		Name: group.Name,
+		ID:   group.ID,
+		Type: group.Type,
+		Err:  err,
+	}
+}
+
+// schedule returns the replication schedule stored in the msDFSR-Schedule
+// attribute of o, or nil if o does not have one.
+func schedule(o directory.Object) (*core.Schedule, error) {
+	data, err := o.AttrBytes("msDFSR-Schedule")
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return core.ParseSchedule(data)
+}
+
+// groupAttributes reads the descriptive attributes of the replication group g
+// into group. The name of the group must already be set.
+func groupAttributes(g directory.Object, group *core.Group) (err error) {
+	groupType, err := optionalInt(g, "msDFSR-ReplicationGroupType")
+	if err != nil {
+		return
+	}
+	group.Type = core.GroupType(groupType)
+	if group.Type == core.GroupTypeData && isSysvolName(group.Name) {
+		// Older configurations do not record the type of the SYSVOL group
+		group.Type = core.GroupTypeSysvol
+	}
+
+	if group.Description, err = g.AttrString("description"); err != nil {
+		return
+	}
+	if group.Version, err = g.AttrString("msDFSR-Version"); err != nil {
+		return
+	}
+	if group.Flags, err = optionalInt(g, "msDFSR-Flags"); err != nil {
+		return
+	}
+	group.Options, err = optionalInt(g, "msDFSR-Options")
+	return
+}
+
+// connectionAttributes reads the remote differential compression settings,
+// keywords and options of the DFSR connection c into conn. Remote
+// differential compression is enabled unless the connection disables it.
+func connectionAttributes(c directory.Object, conn *core.Connection) (err error) {
+	if conn.RDC, err = optionalBool(c, "msDFSR-RdcEnabled", true); err != nil {
+		return
+	}
+	if conn.Keywords, err = c.AttrString("msDFSR-Keywords"); err != nil {
+		return
+	}
+	if conn.Options, err = optionalInt(c, "msDFSR-Options"); err != nil {
+		return
+	}
+	conn.CrossFileRDC = conn.RDC && conn.Options&connectionOptionNoCrossFileRDC == 0
+	return
+}
+
+// optionalInt returns the value of an integer attribute of o, or zero if o
+// does not have the attribute.
+func optionalInt(o directory.Object, name string) (int, error) {
+	value, err := o.AttrInt(name)
+	if errors.Is(err, directory.ErrNotFound) {
+		return 0, nil
+	}
+	return value, err
+}
+
+// optionalInt64 returns the value of a large integer attribute of o, or zero
+// if o does not have the attribute.
+func optionalInt64(o directory.Object, name string) (int64, error) {
+	value, err := o.AttrInt64(name)
+	if errors.Is(err, directory.ErrNotFound) {
+		return 0, nil
+	}
+	return value, err
+}
+
+// fileTime converts a timestamp attribute value, which is a Windows file time,
+// to a time. Zero and the maximum value, which both mean never, are converted
+// to the zero time.
+func fileTime(value int64) time.Time {
+	if value <= 0 || value == math.MaxInt64 {
+		return time.Time{}
+	}
+	return time.Unix(0, (value-fileTimeEpoch)*100).UTC()
+}
+
+// optionalBool returns the value of a boolean attribute of o, or fallback if
+// o does not have the attribute.
+func optionalBool(o directory.Object, name string, fallback bool) (bool, error) {
+	value, err := o.AttrBool(name)
+	if errors.Is(err, directory.ErrNotFound) {
+		return fallback, nil
+	}
+	return value, err
+}
+
+// setScheduleZone applies the time zone of a replication group, which is
+// determined by its options, to its schedule and to the schedules of its
+// connections.
+func setScheduleZone(group *core.Group) {
+	utc := group.Options&groupOptionUTC != 0
+	if group.Schedule != nil {
+		group.Schedule.Local = !utc
+	}
+	for m := range group.Members {
+		for c := range group.Members[m].Connections {
+			if s := group.Members[m].Connections[c].Schedule; s != nil {
+				s.Local = !utc
+			}
+		}
+	}
+}
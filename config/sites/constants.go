@@ -0,0 +1,9 @@
+package sites
+
+import "errors"
+
+var (
+	// ErrInvalidSubnet is returned when the name of a subnet object is not a
+	// valid CIDR prefix.
+	ErrInvalidSubnet = errors.New("The subnet name is not a valid CIDR prefix.")
+)
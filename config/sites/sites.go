@@ -0,0 +1,260 @@
+// Package sites retrieves the Active Directory site topology from the
+// configuration partition and determines the site of each DFSR member.
+//
+// The site of a computer is taken from the server object that refers to it,
+// which exists for domain controllers. The site of any other computer is
+// determined by resolving its host name and matching its addresses against
+// the subnets assigned to each site, as the computer itself would.
+package sites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/adsi.v0"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/adsidir"
+	"gopkg.in/dfsr.v0/core"
+)
+
+// Sites provides a means of querying the Active Directory site topology.
+type Sites struct {
+	dir      directory.Directory
+	resolver Resolver
+}
+
+// New returns a new site topology manager that performs its queries with the
+// given ADSI client.
+//
+// The provided ADSI client is retained by the manager. It is the caller's
+// responsibility to close the client at an appropriate time.
+func New(client *adsi.Client) *Sites {
+	return NewWithDirectory(adsidir.New(client))
+}
+
+// NewWithDirectory returns a new site topology manager that performs its
+// queries against the provided directory.
+//
+// The directory is retained by the manager. It is the caller's responsibility
+// to close the directory at an appropriate time.
+func NewWithDirectory(dir directory.Directory) *Sites {
+	return NewWithConfig(dir, Config{})
+}
+
+// NewWithConfig returns a new site topology manager that performs its queries
+// against the provided directory with the given configuration.
+//
+// The directory is retained by the manager. It is the caller's responsibility
+// to close the directory at an appropriate time.
+func NewWithConfig(dir directory.Directory, config Config) *Sites {
+	resolver := config.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Sites{
+		dir:      dir,
+		resolver: resolver,
+	}
+}
+
+// Topology retrieves the sites, subnets, servers and IP site links of the
+// forest.
+//
+// If the directory does not advertise a configuration naming context, or the
+// configuration partition has no sites container, a nil topology is returned
+// without error.
+func (s *Sites) Topology(ctx context.Context) (t *Topology, err error) {
+	configDN, err := s.configurationDN()
+	if err != nil || configDN == "" {
+		return nil, err
+	}
+
+	sitesDN := combineDN("CN=Sites", configDN)
+	siteObjects, err := s.objects(ctx, sitesDN, "site", nil, 1)
+	if err != nil {
+		if errors.Is(err, directory.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer closeAll(siteObjects)
+
+	t = &Topology{
+		servers:  make(map[string]string),
+		hosts:    make(map[string]string),
+		resolver: s.resolver,
+	}
+
+	// Sites
+	siteIndex := make(map[string]int, len(siteObjects))
+	for _, o := range siteObjects {
+		var site core.Site
+		if site.DN, err = o.DN(); err != nil {
+			return nil, err
+		}
+		if site.ID, err = o.GUID(); err != nil {
+			return nil, err
+		}
+		site.Name = rdnValue(site.DN)
+		siteIndex[dnKey(site.DN)] = len(t.Sites)
+		t.Sites = append(t.Sites, site)
+	}
+
+	// Subnets
+	subnetObjects, err := s.objects(ctx, combineDN("CN=Subnets", sitesDN), "subnet", []string{"siteObject"}, 1)
+	if err != nil && !errors.Is(err, directory.ErrNotFound) {
+		return nil, err
+	}
+	defer closeAll(subnetObjects)
+	for _, o := range subnetObjects {
+		dn, derr := o.DN()
+		if derr != nil {
+			return nil, derr
+		}
+		siteDN, serr := o.AttrString("siteObject")
+		if serr != nil {
+			return nil, serr
+		}
+		i, ok := siteIndex[dnKey(siteDN)]
+		if !ok {
+			continue // Subnet is not assigned to a site
+		}
+		prefix := rdnValue(dn)
+		_, network, perr := net.ParseCIDR(prefix)
+		if perr != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSubnet, prefix)
+		}
+		t.Sites[i].Subnets = append(t.Sites[i].Subnets, prefix)
+		t.subnets = append(t.subnets, subnet{network: network, site: t.Sites[i].Name})
+	}
+
+	// Prefer the most specific subnet when matching addresses
+	sort.SliceStable(t.subnets, func(i, j int) bool {
+		a, _ := t.subnets[i].network.Mask.Size()
+		b, _ := t.subnets[j].network.Mask.Size()
+		return a > b
+	})
+
+	// Servers are located at CN=<server>,CN=Servers,CN=<site>,CN=Sites
+	serverObjects, err := s.objects(ctx, sitesDN, "server", []string{"serverReference", "dNSHostName"}, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAll(serverObjects)
+	for _, o := range serverObjects {
+		dn, derr := o.DN()
+		if derr != nil {
+			return nil, derr
+		}
+		i, ok := siteIndex[dnKey(ancestor(dn, 2))]
+		if !ok {
+			continue
+		}
+		site := t.Sites[i].Name
+		computerDN, cerr := o.AttrString("serverReference")
+		if cerr != nil {
+			return nil, cerr
+		}
+		if computerDN != "" {
+			t.servers[dnKey(computerDN)] = site
+		}
+		host, herr := o.AttrString("dNSHostName")
+		if herr != nil {
+			return nil, herr
+		}
+		if host != "" {
+			t.hosts[strings.ToLower(host)] = site
+		}
+	}
+
+	// Site links
+	linksDN := combineDN("CN=IP,CN=Inter-Site Transports", sitesDN)
+	linkObjects, err := s.objects(ctx, linksDN, "siteLink", []string{"cost", "replInterval", "siteList"}, 1)
+	if err != nil && !errors.Is(err, directory.ErrNotFound) {
+		return nil, err
+	}
+	defer closeAll(linkObjects)
+	for _, o := range linkObjects {
+		link, lerr := siteLink(o, t.Sites, siteIndex)
+		if lerr != nil {
+			return nil, lerr
+		}
+		t.Links = append(t.Links, link)
+	}
+
+	return t, nil
+}
+
+// configurationDN returns the distinguished name of the configuration
+// partition advertised by the root DSE.
+func (s *Sites) configurationDN() (dn string, err error) {
+	rootDSE, err := s.dir.RootDSE()
+	if err != nil {
+		return
+	}
+	defer rootDSE.Close()
+
+	return rootDSE.AttrString("configurationNamingContext")
+}
+
+// objects returns the objects of the given class beneath the base object. It
+// performs a subtree search if the directory supports it and otherwise walks
+// the tree to the given depth. The caller is responsible for closing the
+// returned objects.
+func (s *Sites) objects(ctx context.Context, base, class string, attributes []string, depth int) (objects []directory.Object, err error) {
+	if searcher, ok := s.dir.(directory.Searcher); ok {
+		return searcher.Search(ctx, base, class, attributes)
+	}
+
+	root, err := s.dir.Open(base)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	if err = walk(ctx, root, class, depth, &objects); err != nil {
+		closeAll(objects)
+		return nil, err
+	}
+	return
+}
+
+// siteLink returns the site link represented by o. The names of the sites it
+// connects are looked up in sites.
+func siteLink(o directory.Object, sites []core.Site, siteIndex map[string]int) (link core.SiteLink, err error) {
+	if link.DN, err = o.DN(); err != nil {
+		return
+	}
+	if link.ID, err = o.GUID(); err != nil {
+		return
+	}
+	link.Name = rdnValue(link.DN)
+
+	if link.Cost, err = optionalInt(o, "cost"); err != nil {
+		return
+	}
+
+	interval, err := optionalInt(o, "replInterval")
+	if err != nil {
+		return
+	}
+	link.Interval = time.Duration(interval) * time.Minute
+
+	siteDNs, err := o.AttrStrings("siteList")
+	if err != nil {
+		return
+	}
+	for _, siteDN := range siteDNs {
+		if i, ok := siteIndex[dnKey(siteDN)]; ok {
+			link.Sites = append(link.Sites, sites[i].Name)
+		} else {
+			link.Sites = append(link.Sites, rdnValue(siteDN))
+		}
+	}
+	return
+}
@@ -0,0 +1,147 @@
+package sites
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+)
+
+// Topology is the site topology of a forest.
+type Topology struct {
+	Sites []core.Site
+	Links []core.SiteLink
+
+	servers  map[string]string // Maps computer distinguished names to sites
+	hosts    map[string]string // Maps server host names to sites
+	subnets  []subnet          // Ordered from most to least specific
+	resolver Resolver
+}
+
+// Site returns the name of the site that the given computer belongs to. It
+// returns an empty string if the site cannot be determined.
+//
+// Computers that are referenced by a server object belong to the server's
+// site. The host names of other computers are resolved and their addresses
+// are matched against the subnets of each site.
+func (t *Topology) Site(ctx context.Context, computer core.Computer) (site string, err error) {
+	if t == nil {
+		return "", nil
+	}
+
+	if computer.DN != "" {
+		if site, ok := t.servers[dnKey(computer.DN)]; ok {
+			return site, nil
+		}
+	}
+
+	if computer.Host == "" {
+		return "", nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(computer.Host, "."))
+	if site, ok := t.hosts[host]; ok {
+		return site, nil
+	}
+
+	if len(t.subnets) == 0 {
+		return "", nil
+	}
+
+	addrs, err := t.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+
+	for _, addr := range addrs {
+		if site = t.match(addr.IP); site != "" {
+			return site, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Apply records the sites and site links of the topology in the domain and
+// sets the site of every member and connection computer in its groups and of
+// every domain controller.
+//
+// Computers whose site cannot be determined are left without a site. An
+// error is only returned if ctx is cancelled.
+func (t *Topology) Apply(ctx context.Context, domain *core.Domain) error {
+	if t == nil {
+		return nil
+	}
+
+	domain.Sites = t.Sites
+	domain.SiteLinks = t.Links
+
+	cache := make(map[core.Computer]string)
+	for g := range domain.Groups {
+		if err := t.applyGroup(ctx, &domain.Groups[g], cache); err != nil {
+			return err
+		}
+	}
+	if domain.Sysvol != nil {
+		for i := range domain.Sysvol.DomainControllers {
+			if err := t.assign(ctx, &domain.Sysvol.DomainControllers[i].Computer, cache); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyGroup sets the site of every member and connection computer in the
+// group.
+//
+// Computers whose site cannot be determined are left without a site. An
+// error is only returned if ctx is cancelled.
+func (t *Topology) ApplyGroup(ctx context.Context, group *core.Group) error {
+	if t == nil {
+		return nil
+	}
+	return t.applyGroup(ctx, group, make(map[core.Computer]string))
+}
+
+func (t *Topology) applyGroup(ctx context.Context, group *core.Group, cache map[core.Computer]string) error {
+	for m := range group.Members {
+		member := &group.Members[m]
+		if err := t.assign(ctx, &member.Computer, cache); err != nil {
+			return err
+		}
+		for c := range member.Connections {
+			if err := t.assign(ctx, &member.Connections[c].Computer, cache); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// assign sets the site of the computer, consulting and updating the cache of
+// sites that have already been determined.
+func (t *Topology) assign(ctx context.Context, computer *core.Computer, cache map[core.Computer]string) error {
+	key := core.Computer{DN: computer.DN, Host: computer.Host}
+	site, ok := cache[key]
+	if !ok {
+		site, _ = t.Site(ctx, key)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cache[key] = site
+	}
+	computer.Site = site
+	return nil
+}
+
+// match returns the site of the most specific subnet that contains ip.
+func (t *Topology) match(ip net.IP) string {
+	for _, s := range t.subnets {
+		if s.network.Contains(ip) {
+			return s.site
+		}
+	}
+	return ""
+}
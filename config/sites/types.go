@@ -0,0 +1,27 @@
+package sites
+
+import (
+	"context"
+	"net"
+)
+
+// Resolver resolves host names to IP addresses. It is satisfied by
+// *net.Resolver.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Config holds configuration settings for site queries.
+type Config struct {
+	// Resolver is used to determine the site of computers that do not have a
+	// server object in the configuration partition by matching their
+	// addresses against the subnets of each site. If nil,
+	// net.DefaultResolver is used.
+	Resolver Resolver
+}
+
+// subnet associates an IP network with a site.
+type subnet struct {
+	network *net.IPNet
+	site    string
+}
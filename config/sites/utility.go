@@ -0,0 +1,107 @@
+package sites
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gopkg.in/dfsr.v0/config/directory"
+)
+
+func combineDN(components ...string) string {
+	return strings.Join(components, ",")
+}
+
+// dnKey returns a normalized form of a distinguished name that is suitable
+// for use as a map key.
+func dnKey(dn string) string {
+	var parts []string
+	for dn != "" {
+		var rdn string
+		rdn, dn = directory.SplitDN(dn)
+		parts = append(parts, strings.ToLower(rdn))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ancestor returns the distinguished name of the ancestor of dn that is the
+// given number of levels above it.
+func ancestor(dn string, levels int) string {
+	for i := 0; i < levels; i++ {
+		_, dn = directory.SplitDN(dn)
+	}
+	return dn
+}
+
+// rdnValue returns the value of the relative distinguished name of dn, such
+// as "Default-First-Site-Name" for "CN=Default-First-Site-Name,CN=Sites".
+func rdnValue(dn string) string {
+	rdn, _ := directory.SplitDN(dn)
+	if i := strings.Index(rdn, "="); i >= 0 {
+		rdn = rdn[i+1:]
+	}
+	return strings.Replace(rdn, "\\", "", -1)
+}
+
+// optionalInt returns the value of an integer attribute of o, or zero if o
+// does not have the attribute.
+func optionalInt(o directory.Object, name string) (int, error) {
+	value, err := o.AttrInt(name)
+	if errors.Is(err, directory.ErrNotFound) {
+		return 0, nil
+	}
+	return value, err
+}
+
+// walk appends the descendants of o that are of the given class and no more
+// than depth levels beneath it to objects.
+func walk(ctx context.Context, o directory.Object, class string, depth int, objects *[]directory.Object) error {
+	if depth <= 0 {
+		return nil
+	}
+
+	iter, err := o.Children()
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for {
+		child, cerr := iter.Next()
+		if errors.Is(cerr, directory.ErrDone) {
+			break
+		}
+		if cerr != nil {
+			return cerr
+		}
+		if err = ctx.Err(); err != nil {
+			child.Close()
+			return err
+		}
+
+		childClass, err := child.Class()
+		if err != nil {
+			child.Close()
+			return err
+		}
+
+		if err = walk(ctx, child, class, depth-1, objects); err != nil {
+			child.Close()
+			return err
+		}
+
+		if strings.EqualFold(childClass, class) {
+			*objects = append(*objects, child)
+		} else {
+			child.Close()
+		}
+	}
+
+	return nil
+}
+
+func closeAll(objects []directory.Object) {
+	for _, o := range objects {
+		o.Close()
+	}
+}
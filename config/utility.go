@@ -1,9 +1,16 @@
 package config
 
-import "gopkg.in/adsi.v0"
+import (
+	"strings"
 
-func dnc(client *adsi.Client) (dnc string, err error) {
-	rootDSE, err := client.Open("LDAP://RootDSE")
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/core"
+
+	"github.com/go-ole/go-ole"
+)
+
+func dnc(dir directory.Directory) (dnc string, err error) {
+	rootDSE, err := dir.RootDSE()
 	if err != nil {
 		return
 	}
@@ -11,3 +18,40 @@ func dnc(client *adsi.Client) (dnc string, err error) {
 
 	return rootDSE.AttrString("rootDomainNamingContext")
 }
+
+// retainFailed returns a copy of current in which groups that could not be
+// retrieved are replaced by their last known configuration in previous. This
+// allows changes to be detected when a group recovers.
+func retainFailed(previous, current *core.Domain) *core.Domain {
+	if previous == nil || len(current.Failed()) == 0 {
+		return current
+	}
+
+	retained := *current
+	retained.Groups = make([]core.Group, len(current.Groups))
+	copy(retained.Groups, current.Groups)
+
+	for g := range retained.Groups {
+		group := &retained.Groups[g]
+		if group.Err == nil {
+			continue
+		}
+		for p := range previous.Groups {
+			old := &previous.Groups[p]
+			if old.Err == nil && sameGroup(old, group) {
+				*group = *old
+				break
+			}
+		}
+	}
+
+	return &retained
+}
+
+// sameGroup reports whether a and b refer to the same replication group.
+func sameGroup(a, b *core.Group) bool {
+	if a.ID != nil && b.ID != nil {
+		return ole.IsEqualGUID(a.ID, b.ID)
+	}
+	return strings.EqualFold(a.Name, b.Name)
+}
@@ -0,0 +1,1230 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/dfsr.v0/callstat"
+
+	"github.com/go-ole/go-ole"
+)
+
+// The types in this package are encoded as JSON and YAML through the data
+// types declared below. GUIDs are encoded in their canonical string form and
+// durations in the form used by time.Duration.String. Backlogs refer to their
+// replication group and folders by name and ID instead of embedding them.
+
+// guidText is a GUID that is encoded as text.
+type guidText ole.GUID
+
+func newGUIDText(id *ole.GUID) *guidText {
+	return (*guidText)(id)
+}
+
+func (g *guidText) guid() *ole.GUID {
+	return (*ole.GUID)(g)
+}
+
+// MarshalText returns the canonical string form of the GUID.
+func (g guidText) MarshalText() ([]byte, error) {
+	return []byte(g.guid().String()), nil
+}
+
+// UnmarshalText parses a GUID from its string form.
+func (g *guidText) UnmarshalText(text []byte) error {
+	id := ole.NewGUID(string(text))
+	if id == nil {
+		return fmt.Errorf("invalid GUID \"%s\"", text)
+	}
+	*g = guidText(*id)
+	return nil
+}
+
+// durationText is a duration that is encoded as human-readable text.
+type durationText time.Duration
+
+// MarshalText returns the string form of the duration.
+func (d durationText) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses a duration from its string form.
+func (d *durationText) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = durationText(value)
+	return nil
+}
+
+type namingContextData struct {
+	ID          *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	DN          string    `json:"dn,omitempty" yaml:"dn,omitempty"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Path        string    `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+type domainData struct {
+	namingContextData `yaml:",inline"`
+	Groups            []Group         `json:"groups" yaml:"groups"`
+	Sites             []Site          `json:"sites,omitempty" yaml:"sites,omitempty"`
+	SiteLinks         []SiteLink      `json:"siteLinks,omitempty" yaml:"siteLinks,omitempty"`
+	SitesErr          *callstat.Error `json:"sitesError,omitempty" yaml:"sitesError,omitempty"`
+	Namespaces        []Namespace     `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	NamespacesErr     *callstat.Error `json:"namespacesError,omitempty" yaml:"namespacesError,omitempty"`
+	Sysvol            *Sysvol         `json:"sysvol,omitempty" yaml:"sysvol,omitempty"`
+	SysvolErr         *callstat.Error `json:"sysvolError,omitempty" yaml:"sysvolError,omitempty"`
+	ConfigDuration    durationText    `json:"configDuration,omitempty" yaml:"configDuration,omitempty"`
+}
+
+type namespaceData struct {
+	Name    string    `json:"name" yaml:"name"`
+	ID      *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	Path    string    `json:"path" yaml:"path"`
+	Comment string    `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Targets []Target  `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Links   []Link    `json:"links,omitempty" yaml:"links,omitempty"`
+}
+
+type linkData struct {
+	Name    string    `json:"name" yaml:"name"`
+	ID      *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	Path    string    `json:"path" yaml:"path"`
+	Comment string    `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Targets []Target  `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+type siteData struct {
+	Name    string    `json:"name" yaml:"name"`
+	ID      *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	DN      string    `json:"dn,omitempty" yaml:"dn,omitempty"`
+	Subnets []string  `json:"subnets,omitempty" yaml:"subnets,omitempty"`
+}
+
+type siteLinkData struct {
+	Name     string       `json:"name" yaml:"name"`
+	ID       *guidText    `json:"id,omitempty" yaml:"id,omitempty"`
+	DN       string       `json:"dn,omitempty" yaml:"dn,omitempty"`
+	Cost     int          `json:"cost" yaml:"cost"`
+	Interval durationText `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Sites    []string     `json:"sites" yaml:"sites"`
+}
+
+type groupData struct {
+	Name           string          `json:"name" yaml:"name"`
+	ID             *guidText       `json:"id,omitempty" yaml:"id,omitempty"`
+	Domain         string          `json:"domain,omitempty" yaml:"domain,omitempty"`
+	Type           GroupType       `json:"type" yaml:"type"`
+	Description    string          `json:"description,omitempty" yaml:"description,omitempty"`
+	Version        string          `json:"version,omitempty" yaml:"version,omitempty"`
+	Flags          int             `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Options        int             `json:"options,omitempty" yaml:"options,omitempty"`
+	Folders        []Folder        `json:"folders" yaml:"folders"`
+	Members        []Member        `json:"members" yaml:"members"`
+	Schedule       *Schedule       `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	ConfigDuration durationText    `json:"configDuration,omitempty" yaml:"configDuration,omitempty"`
+	Err            *callstat.Error `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// groupRef identifies a replication group within a backlog.
+type groupRef struct {
+	Name string    `json:"name" yaml:"name"`
+	ID   *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+type folderData struct {
+	Name            string    `json:"name" yaml:"name"`
+	ID              *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	Description     string    `json:"description,omitempty" yaml:"description,omitempty"`
+	FileFilter      Filter    `json:"fileFilter,omitempty" yaml:"fileFilter,omitempty"`
+	DirectoryFilter Filter    `json:"directoryFilter,omitempty" yaml:"directoryFilter,omitempty"`
+	DfsPath         string    `json:"dfsPath,omitempty" yaml:"dfsPath,omitempty"`
+}
+
+type memberInfoData struct {
+	Name     string    `json:"name" yaml:"name"`
+	ID       *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	DN       string    `json:"dn,omitempty" yaml:"dn,omitempty"`
+	Computer Computer  `json:"computer" yaml:"computer"`
+}
+
+type memberData struct {
+	memberInfoData `yaml:",inline"`
+	Connections    []Connection   `json:"connections" yaml:"connections"`
+	Subscriptions  []Subscription `json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+}
+
+type subscriptionData struct {
+	Folder        folderData `json:"folder" yaml:"folder"`
+	DN            string     `json:"dn,omitempty" yaml:"dn,omitempty"`
+	RootPath      string     `json:"rootPath,omitempty" yaml:"rootPath,omitempty"`
+	StagingPath   string     `json:"stagingPath,omitempty" yaml:"stagingPath,omitempty"`
+	StagingQuota  int        `json:"stagingQuota,omitempty" yaml:"stagingQuota,omitempty"`
+	ConflictPath  string     `json:"conflictPath,omitempty" yaml:"conflictPath,omitempty"`
+	ConflictQuota int        `json:"conflictQuota,omitempty" yaml:"conflictQuota,omitempty"`
+	ReadOnly      bool       `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	Enabled       bool       `json:"enabled" yaml:"enabled"`
+}
+
+type computerData struct {
+	DN                     string     `json:"dn,omitempty" yaml:"dn,omitempty"`
+	Host                   string     `json:"host,omitempty" yaml:"host,omitempty"`
+	Site                   string     `json:"site,omitempty" yaml:"site,omitempty"`
+	OperatingSystem        string     `json:"operatingSystem,omitempty" yaml:"operatingSystem,omitempty"`
+	OperatingSystemVersion string     `json:"operatingSystemVersion,omitempty" yaml:"operatingSystemVersion,omitempty"`
+	LastLogon              *time.Time `json:"lastLogon,omitempty" yaml:"lastLogon,omitempty"`
+	Disabled               bool       `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	DomainController       bool       `json:"domainController,omitempty" yaml:"domainController,omitempty"`
+}
+
+type connectionData struct {
+	Name         string    `json:"name" yaml:"name"`
+	ID           *guidText `json:"id,omitempty" yaml:"id,omitempty"`
+	MemberDN     string    `json:"memberDn,omitempty" yaml:"memberDn,omitempty"`
+	Enabled      bool      `json:"enabled" yaml:"enabled"`
+	Computer     Computer  `json:"computer" yaml:"computer"`
+	Schedule     *Schedule `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	RDC          bool      `json:"rdc" yaml:"rdc"`
+	CrossFileRDC bool      `json:"crossFileRdc" yaml:"crossFileRdc"`
+	Keywords     string    `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Options      int       `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// scheduleData represents a schedule as one string per day of the week,
+// starting with Sunday. Each character of a string is the hexadecimal
+// bandwidth level of a quarter-hour.
+type scheduleData struct {
+	Local bool     `json:"local,omitempty" yaml:"local,omitempty"`
+	Days  []string `json:"days" yaml:"days"`
+}
+
+type sysvolData struct {
+	State             SysvolState        `json:"state" yaml:"state"`
+	DomainControllers []DomainController `json:"domainControllers,omitempty" yaml:"domainControllers,omitempty"`
+}
+
+type domainControllerData struct {
+	Computer Computer    `json:"computer" yaml:"computer"`
+	ReadOnly bool        `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	State    SysvolState `json:"state" yaml:"state"`
+	Member   string      `json:"member,omitempty" yaml:"member,omitempty"`
+}
+
+type folderBacklogData struct {
+	Folder  *folderData `json:"folder,omitempty" yaml:"folder,omitempty"`
+	Backlog int         `json:"backlog" yaml:"backlog"`
+}
+
+type backlogData struct {
+	Group        *groupRef           `json:"group,omitempty" yaml:"group,omitempty"`
+	From         string              `json:"from" yaml:"from"`
+	To           string              `json:"to" yaml:"to"`
+	FromComputer *Computer           `json:"fromComputer,omitempty" yaml:"fromComputer,omitempty"`
+	ToComputer   *Computer           `json:"toComputer,omitempty" yaml:"toComputer,omitempty"`
+	Folders      []folderBacklogData `json:"folders" yaml:"folders"`
+	Unscheduled  bool                `json:"unscheduled,omitempty" yaml:"unscheduled,omitempty"`
+	Call         callstat.Call       `json:"call" yaml:"call"`
+	Err          *callstat.Error     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (nc *NamingContext) data() namingContextData {
+	return namingContextData{
+		ID:          newGUIDText(nc.ID),
+		DN:          nc.DN,
+		Description: nc.Description,
+		Path:        nc.Path,
+	}
+}
+
+func (data *namingContextData) value() NamingContext {
+	return NamingContext{
+		ID:          data.ID.guid(),
+		DN:          data.DN,
+		Description: data.Description,
+		Path:        data.Path,
+	}
+}
+
+func (d *Domain) data() domainData {
+	return domainData{
+		namingContextData: d.NamingContext.data(),
+		Groups:            d.Groups,
+		Sites:             d.Sites,
+		SiteLinks:         d.SiteLinks,
+		SitesErr:          callstat.NewError(d.SitesErr),
+		Namespaces:        d.Namespaces,
+		NamespacesErr:     callstat.NewError(d.NamespacesErr),
+		Sysvol:            d.Sysvol,
+		SysvolErr:         callstat.NewError(d.SysvolErr),
+		ConfigDuration:    durationText(d.ConfigDuration),
+	}
+}
+
+func (data *domainData) value() Domain {
+	return Domain{
+		NamingContext:  data.namingContextData.value(),
+		Groups:         data.Groups,
+		Sites:          data.Sites,
+		SiteLinks:      data.SiteLinks,
+		SitesErr:       data.SitesErr.Decode(),
+		Namespaces:     data.Namespaces,
+		NamespacesErr:  data.NamespacesErr.Decode(),
+		Sysvol:         data.Sysvol,
+		SysvolErr:      data.SysvolErr.Decode(),
+		ConfigDuration: time.Duration(data.ConfigDuration),
+	}
+}
+
+func (ns *Namespace) data() namespaceData {
+	return namespaceData{
+		Name:    ns.Name,
+		ID:      newGUIDText(ns.ID),
+		Path:    ns.Path,
+		Comment: ns.Comment,
+		Targets: ns.Targets,
+		Links:   ns.Links,
+	}
+}
+
+func (data *namespaceData) value() Namespace {
+	return Namespace{
+		Name:    data.Name,
+		ID:      data.ID.guid(),
+		Path:    data.Path,
+		Comment: data.Comment,
+		Targets: data.Targets,
+		Links:   data.Links,
+	}
+}
+
+func (l *Link) data() linkData {
+	return linkData{
+		Name:    l.Name,
+		ID:      newGUIDText(l.ID),
+		Path:    l.Path,
+		Comment: l.Comment,
+		Targets: l.Targets,
+	}
+}
+
+func (data *linkData) value() Link {
+	return Link{
+		Name:    data.Name,
+		ID:      data.ID.guid(),
+		Path:    data.Path,
+		Comment: data.Comment,
+		Targets: data.Targets,
+	}
+}
+
+func (s *Site) data() siteData {
+	return siteData{
+		Name:    s.Name,
+		ID:      newGUIDText(s.ID),
+		DN:      s.DN,
+		Subnets: s.Subnets,
+	}
+}
+
+func (data *siteData) value() Site {
+	return Site{
+		Name:    data.Name,
+		ID:      data.ID.guid(),
+		DN:      data.DN,
+		Subnets: data.Subnets,
+	}
+}
+
+func (l *SiteLink) data() siteLinkData {
+	return siteLinkData{
+		Name:     l.Name,
+		ID:       newGUIDText(l.ID),
+		DN:       l.DN,
+		Cost:     l.Cost,
+		Interval: durationText(l.Interval),
+		Sites:    l.Sites,
+	}
+}
+
+func (data *siteLinkData) value() SiteLink {
+	return SiteLink{
+		Name:     data.Name,
+		ID:       data.ID.guid(),
+		DN:       data.DN,
+		Cost:     data.Cost,
+		Interval: time.Duration(data.Interval),
+		Sites:    data.Sites,
+	}
+}
+
+func (g *Group) data() groupData {
+	return groupData{
+		Name:           g.Name,
+		ID:             newGUIDText(g.ID),
+		Domain:         g.Domain,
+		Type:           g.Type,
+		Description:    g.Description,
+		Version:        g.Version,
+		Flags:          g.Flags,
+		Options:        g.Options,
+		Folders:        g.Folders,
+		Members:        g.Members,
+		Schedule:       g.Schedule,
+		ConfigDuration: durationText(g.ConfigDuration),
+		Err:            callstat.NewError(g.Err),
+	}
+}
+
+func (data *groupData) value() Group {
+	return Group{
+		Name:           data.Name,
+		ID:             data.ID.guid(),
+		Domain:         data.Domain,
+		Type:           data.Type,
+		Description:    data.Description,
+		Version:        data.Version,
+		Flags:          data.Flags,
+		Options:        data.Options,
+		Folders:        data.Folders,
+		Members:        data.Members,
+		Schedule:       data.Schedule,
+		ConfigDuration: time.Duration(data.ConfigDuration),
+		Err:            data.Err.Decode(),
+	}
+}
+
+func (f *Folder) data() folderData {
+	return folderData{
+		Name:            f.Name,
+		ID:              newGUIDText(f.ID),
+		Description:     f.Description,
+		FileFilter:      f.FileFilter,
+		DirectoryFilter: f.DirectoryFilter,
+		DfsPath:         f.DfsPath,
+	}
+}
+
+func (data *folderData) value() Folder {
+	return Folder{
+		Name:            data.Name,
+		ID:              data.ID.guid(),
+		Description:     data.Description,
+		FileFilter:      data.FileFilter,
+		DirectoryFilter: data.DirectoryFilter,
+		DfsPath:         data.DfsPath,
+	}
+}
+
+func (m *MemberInfo) data() memberInfoData {
+	return memberInfoData{
+		Name:     m.Name,
+		ID:       newGUIDText(m.ID),
+		DN:       m.DN,
+		Computer: m.Computer,
+	}
+}
+
+func (data *memberInfoData) value() MemberInfo {
+	return MemberInfo{
+		Name:     data.Name,
+		ID:       data.ID.guid(),
+		DN:       data.DN,
+		Computer: data.Computer,
+	}
+}
+
+func (m *Member) data() memberData {
+	return memberData{
+		memberInfoData: m.MemberInfo.data(),
+		Connections:    m.Connections,
+		Subscriptions:  m.Subscriptions,
+	}
+}
+
+func (data *memberData) value() Member {
+	return Member{
+		MemberInfo:    data.memberInfoData.value(),
+		Connections:   data.Connections,
+		Subscriptions: data.Subscriptions,
+	}
+}
+
+func (s *Subscription) data() subscriptionData {
+	return subscriptionData{
+		Folder:        s.Folder.data(),
+		DN:            s.DN,
+		RootPath:      s.RootPath,
+		StagingPath:   s.StagingPath,
+		StagingQuota:  s.StagingQuota,
+		ConflictPath:  s.ConflictPath,
+		ConflictQuota: s.ConflictQuota,
+		ReadOnly:      s.ReadOnly,
+		Enabled:       s.Enabled,
+	}
+}
+
+func (data *subscriptionData) value() Subscription {
+	return Subscription{
+		Folder:        data.Folder.value(),
+		DN:            data.DN,
+		RootPath:      data.RootPath,
+		StagingPath:   data.StagingPath,
+		StagingQuota:  data.StagingQuota,
+		ConflictPath:  data.ConflictPath,
+		ConflictQuota: data.ConflictQuota,
+		ReadOnly:      data.ReadOnly,
+		Enabled:       data.Enabled,
+	}
+}
+
+func (c *Computer) data() computerData {
+	data := computerData{
+		DN:                     c.DN,
+		Host:                   c.Host,
+		Site:                   c.Site,
+		OperatingSystem:        c.OperatingSystem,
+		OperatingSystemVersion: c.OperatingSystemVersion,
+		Disabled:               c.Disabled,
+		DomainController:       c.DomainController,
+	}
+	if !c.LastLogon.IsZero() {
+		lastLogon := c.LastLogon
+		data.LastLogon = &lastLogon
+	}
+	return data
+}
+
+func (data *computerData) value() Computer {
+	c := Computer{
+		DN:                     data.DN,
+		Host:                   data.Host,
+		Site:                   data.Site,
+		OperatingSystem:        data.OperatingSystem,
+		OperatingSystemVersion: data.OperatingSystemVersion,
+		Disabled:               data.Disabled,
+		DomainController:       data.DomainController,
+	}
+	if data.LastLogon != nil {
+		c.LastLogon = *data.LastLogon
+	}
+	return c
+}
+
+func (c *Connection) data() connectionData {
+	return connectionData{
+		Name:         c.Name,
+		ID:           newGUIDText(c.ID),
+		MemberDN:     c.MemberDN,
+		Enabled:      c.Enabled,
+		Computer:     c.Computer,
+		Schedule:     c.Schedule,
+		RDC:          c.RDC,
+		CrossFileRDC: c.CrossFileRDC,
+		Keywords:     c.Keywords,
+		Options:      c.Options,
+	}
+}
+
+func (data *connectionData) value() Connection {
+	return Connection{
+		Name:         data.Name,
+		ID:           data.ID.guid(),
+		MemberDN:     data.MemberDN,
+		Enabled:      data.Enabled,
+		Computer:     data.Computer,
+		Schedule:     data.Schedule,
+		RDC:          data.RDC,
+		CrossFileRDC: data.CrossFileRDC,
+		Keywords:     data.Keywords,
+		Options:      data.Options,
+	}
+}
+
+func (s *Schedule) data() scheduleData {
+	const perDay = ScheduleIntervals / 7
+	data := scheduleData{Local: s.Local, Days: make([]string, 7)}
+	for d := range data.Days {
+		day := make([]byte, perDay)
+		for i := range day {
+			day[i] = "0123456789abcdef"[s.Intervals[d*perDay+i]&0x0F]
+		}
+		data.Days[d] = string(day)
+	}
+	return data
+}
+
+func (data *scheduleData) value() (Schedule, error) {
+	const perDay = ScheduleIntervals / 7
+	s := Schedule{Local: data.Local}
+	if len(data.Days) != 7 {
+		return s, fmt.Errorf("%w: expected 7 days, received %d", ErrInvalidSchedule, len(data.Days))
+	}
+	for d, day := range data.Days {
+		if len(day) != perDay {
+			return s, fmt.Errorf("%w: expected %d intervals on day %d, received %d", ErrInvalidSchedule, perDay, d, len(day))
+		}
+		for i := 0; i < perDay; i++ {
+			level, err := strconv.ParseUint(day[i:i+1], 16, 4)
+			if err != nil {
+				return s, fmt.Errorf("%w: invalid bandwidth level \"%c\" on day %d", ErrInvalidSchedule, day[i], d)
+			}
+			s.Intervals[d*perDay+i] = Bandwidth(level)
+		}
+	}
+	return s, nil
+}
+
+func (fb *FolderBacklog) data() folderBacklogData {
+	data := folderBacklogData{Backlog: fb.Backlog}
+	if fb.Folder != nil {
+		folder := fb.Folder.data()
+		data.Folder = &folder
+	}
+	return data
+}
+
+func (data *folderBacklogData) value() FolderBacklog {
+	fb := FolderBacklog{Backlog: data.Backlog}
+	if data.Folder != nil {
+		folder := data.Folder.value()
+		fb.Folder = &folder
+	}
+	return fb
+}
+
+func (b *Backlog) data() backlogData {
+	data := backlogData{
+		From:        b.From,
+		To:          b.To,
+		Unscheduled: b.Unscheduled,
+		Call:        b.Call,
+		Err:         callstat.NewError(b.Err),
+	}
+	if b.FromComputer != (Computer{}) {
+		data.FromComputer = &b.FromComputer
+	}
+	if b.ToComputer != (Computer{}) {
+		data.ToComputer = &b.ToComputer
+	}
+	if b.Group != nil {
+		data.Group = &groupRef{
+			Name: b.Group.Name,
+			ID:   newGUIDText(b.Group.ID),
+		}
+	}
+	for f := range b.Folders {
+		data.Folders = append(data.Folders, b.Folders[f].data())
+	}
+	return data
+}
+
+func (data *backlogData) value() Backlog {
+	b := Backlog{
+		From:        data.From,
+		To:          data.To,
+		Unscheduled: data.Unscheduled,
+		Call:        data.Call,
+		Err:         data.Err.Decode(),
+	}
+	if data.FromComputer != nil {
+		b.FromComputer = *data.FromComputer
+	}
+	if data.ToComputer != nil {
+		b.ToComputer = *data.ToComputer
+	}
+	if data.Group != nil {
+		b.Group = &Group{
+			Name: data.Group.Name,
+			ID:   data.Group.ID.guid(),
+		}
+	}
+	for f := range data.Folders {
+		b.Folders = append(b.Folders, data.Folders[f].value())
+	}
+	return b
+}
+
+func (s *Sysvol) data() sysvolData {
+	return sysvolData{
+		State:             s.State,
+		DomainControllers: s.DomainControllers,
+	}
+}
+
+func (data *sysvolData) value() Sysvol {
+	return Sysvol{
+		State:             data.State,
+		DomainControllers: data.DomainControllers,
+	}
+}
+
+func (dc *DomainController) data() domainControllerData {
+	return domainControllerData{
+		Computer: dc.Computer,
+		ReadOnly: dc.ReadOnly,
+		State:    dc.State,
+		Member:   dc.Member,
+	}
+}
+
+func (data *domainControllerData) value() DomainController {
+	return DomainController{
+		Computer: data.Computer,
+		ReadOnly: data.ReadOnly,
+		State:    data.State,
+		Member:   data.Member,
+	}
+}
+
+// MarshalJSON returns a JSON representation of the domain.
+func (d Domain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.data())
+}
+
+// UnmarshalJSON decodes a domain from its JSON representation.
+func (d *Domain) UnmarshalJSON(b []byte) error {
+	var data domainData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*d = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the domain.
+func (d Domain) MarshalYAML() (interface{}, error) {
+	return d.data(), nil
+}
+
+// UnmarshalYAML decodes a domain from its YAML representation.
+func (d *Domain) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data domainData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*d = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the namespace.
+func (ns Namespace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ns.data())
+}
+
+// UnmarshalJSON decodes a namespace from its JSON representation.
+func (ns *Namespace) UnmarshalJSON(b []byte) error {
+	var data namespaceData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*ns = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the namespace.
+func (ns Namespace) MarshalYAML() (interface{}, error) {
+	return ns.data(), nil
+}
+
+// UnmarshalYAML decodes a namespace from its YAML representation.
+func (ns *Namespace) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data namespaceData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*ns = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the namespace link.
+func (l Link) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.data())
+}
+
+// UnmarshalJSON decodes a namespace link from its JSON representation.
+func (l *Link) UnmarshalJSON(b []byte) error {
+	var data linkData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*l = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the namespace link.
+func (l Link) MarshalYAML() (interface{}, error) {
+	return l.data(), nil
+}
+
+// UnmarshalYAML decodes a namespace link from its YAML representation.
+func (l *Link) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data linkData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*l = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the site.
+func (s Site) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.data())
+}
+
+// UnmarshalJSON decodes a site from its JSON representation.
+func (s *Site) UnmarshalJSON(b []byte) error {
+	var data siteData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the site.
+func (s Site) MarshalYAML() (interface{}, error) {
+	return s.data(), nil
+}
+
+// UnmarshalYAML decodes a site from its YAML representation.
+func (s *Site) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data siteData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the site link.
+func (l SiteLink) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.data())
+}
+
+// UnmarshalJSON decodes a site link from its JSON representation.
+func (l *SiteLink) UnmarshalJSON(b []byte) error {
+	var data siteLinkData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*l = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the site link.
+func (l SiteLink) MarshalYAML() (interface{}, error) {
+	return l.data(), nil
+}
+
+// UnmarshalYAML decodes a site link from its YAML representation.
+func (l *SiteLink) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data siteLinkData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*l = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the naming context.
+func (nc NamingContext) MarshalJSON() ([]byte, error) {
+	return json.Marshal(nc.data())
+}
+
+// UnmarshalJSON decodes a naming context from its JSON representation.
+func (nc *NamingContext) UnmarshalJSON(b []byte) error {
+	var data namingContextData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*nc = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the naming context.
+func (nc NamingContext) MarshalYAML() (interface{}, error) {
+	return nc.data(), nil
+}
+
+// UnmarshalYAML decodes a naming context from its YAML representation.
+func (nc *NamingContext) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data namingContextData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*nc = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the replication group.
+func (g Group) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.data())
+}
+
+// UnmarshalJSON decodes a replication group from its JSON representation.
+func (g *Group) UnmarshalJSON(b []byte) error {
+	var data groupData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*g = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the replication group.
+func (g Group) MarshalYAML() (interface{}, error) {
+	return g.data(), nil
+}
+
+// UnmarshalYAML decodes a replication group from its YAML representation.
+func (g *Group) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data groupData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*g = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the replication folder.
+func (f Folder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.data())
+}
+
+// UnmarshalJSON decodes a replication folder from its JSON representation.
+func (f *Folder) UnmarshalJSON(b []byte) error {
+	var data folderData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*f = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the replication folder.
+func (f Folder) MarshalYAML() (interface{}, error) {
+	return f.data(), nil
+}
+
+// UnmarshalYAML decodes a replication folder from its YAML representation.
+func (f *Folder) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data folderData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*f = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the replication member.
+func (m Member) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.data())
+}
+
+// UnmarshalJSON decodes a replication member from its JSON representation.
+func (m *Member) UnmarshalJSON(b []byte) error {
+	var data memberData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*m = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the replication member.
+func (m Member) MarshalYAML() (interface{}, error) {
+	return m.data(), nil
+}
+
+// UnmarshalYAML decodes a replication member from its YAML representation.
+func (m *Member) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data memberData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*m = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the subscription.
+func (s Subscription) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.data())
+}
+
+// UnmarshalJSON decodes a subscription from its JSON representation.
+func (s *Subscription) UnmarshalJSON(b []byte) error {
+	var data subscriptionData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the subscription.
+func (s Subscription) MarshalYAML() (interface{}, error) {
+	return s.data(), nil
+}
+
+// UnmarshalYAML decodes a subscription from its YAML representation.
+func (s *Subscription) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data subscriptionData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the computer.
+func (c Computer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.data())
+}
+
+// UnmarshalJSON decodes a computer from its JSON representation.
+func (c *Computer) UnmarshalJSON(b []byte) error {
+	var data computerData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*c = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the computer.
+func (c Computer) MarshalYAML() (interface{}, error) {
+	return c.data(), nil
+}
+
+// UnmarshalYAML decodes a computer from its YAML representation.
+func (c *Computer) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data computerData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*c = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the member information.
+func (m MemberInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.data())
+}
+
+// UnmarshalJSON decodes member information from its JSON representation.
+func (m *MemberInfo) UnmarshalJSON(b []byte) error {
+	var data memberInfoData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*m = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the member information.
+func (m MemberInfo) MarshalYAML() (interface{}, error) {
+	return m.data(), nil
+}
+
+// UnmarshalYAML decodes member information from its YAML representation.
+func (m *MemberInfo) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data memberInfoData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*m = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the connection.
+func (c Connection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.data())
+}
+
+// UnmarshalJSON decodes a connection from its JSON representation.
+func (c *Connection) UnmarshalJSON(b []byte) error {
+	var data connectionData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*c = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the connection.
+func (c Connection) MarshalYAML() (interface{}, error) {
+	return c.data(), nil
+}
+
+// UnmarshalYAML decodes a connection from its YAML representation.
+func (c *Connection) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data connectionData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*c = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the folder backlog.
+func (fb FolderBacklog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fb.data())
+}
+
+// UnmarshalJSON decodes a folder backlog from its JSON representation.
+func (fb *FolderBacklog) UnmarshalJSON(b []byte) error {
+	var data folderBacklogData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*fb = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the folder backlog.
+func (fb FolderBacklog) MarshalYAML() (interface{}, error) {
+	return fb.data(), nil
+}
+
+// UnmarshalYAML decodes a folder backlog from its YAML representation.
+func (fb *FolderBacklog) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data folderBacklogData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*fb = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the backlog. The replication
+// group and folders of the backlog are identified by name and ID only.
+func (b Backlog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.data())
+}
+
+// UnmarshalJSON decodes a backlog from its JSON representation.
+func (b *Backlog) UnmarshalJSON(data []byte) error {
+	var bd backlogData
+	if err := json.Unmarshal(data, &bd); err != nil {
+		return err
+	}
+	*b = bd.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the backlog. The replication
+// group and folders of the backlog are identified by name and ID only.
+func (b Backlog) MarshalYAML() (interface{}, error) {
+	return b.data(), nil
+}
+
+// UnmarshalYAML decodes a backlog from its YAML representation.
+func (b *Backlog) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var bd backlogData
+	if err := unmarshal(&bd); err != nil {
+		return err
+	}
+	*b = bd.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the schedule.
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.data())
+}
+
+// UnmarshalJSON decodes a schedule from its JSON representation.
+func (s *Schedule) UnmarshalJSON(b []byte) error {
+	var data scheduleData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	value, err := data.value()
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the schedule.
+func (s Schedule) MarshalYAML() (interface{}, error) {
+	return s.data(), nil
+}
+
+// UnmarshalYAML decodes a schedule from its YAML representation.
+func (s *Schedule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data scheduleData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	value, err := data.value()
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the SYSVOL replication.
+func (s Sysvol) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.data())
+}
+
+// UnmarshalJSON decodes SYSVOL replication from its JSON representation.
+func (s *Sysvol) UnmarshalJSON(b []byte) error {
+	var data sysvolData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the SYSVOL replication.
+func (s Sysvol) MarshalYAML() (interface{}, error) {
+	return s.data(), nil
+}
+
+// UnmarshalYAML decodes SYSVOL replication from its YAML representation.
+func (s *Sysvol) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data sysvolData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*s = data.value()
+	return nil
+}
+
+// MarshalJSON returns a JSON representation of the domain controller.
+func (dc DomainController) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dc.data())
+}
+
+// UnmarshalJSON decodes a domain controller from its JSON representation.
+func (dc *DomainController) UnmarshalJSON(b []byte) error {
+	var data domainControllerData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	*dc = data.value()
+	return nil
+}
+
+// MarshalYAML returns a YAML representation of the domain controller.
+func (dc DomainController) MarshalYAML() (interface{}, error) {
+	return dc.data(), nil
+}
+
+// UnmarshalYAML decodes a domain controller from its YAML representation.
+func (dc *DomainController) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data domainControllerData
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*dc = data.value()
+	return nil
+}
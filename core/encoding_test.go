@@ -0,0 +1,172 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/callstat"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/helper/api"
+	"gopkg.in/yaml.v2"
+)
+
+var codecs = []struct {
+	Name      string
+	Marshal   func(interface{}) ([]byte, error)
+	Unmarshal func([]byte, interface{}) error
+}{
+	{"JSON", json.Marshal, json.Unmarshal},
+	{"YAML", yaml.Marshal, yaml.Unmarshal},
+}
+
+func testSchedule() *core.Schedule {
+	s := &core.Schedule{Local: true}
+	for i := range s.Intervals {
+		if i%4 == 0 {
+			s.Intervals[i] = core.BandwidthFull
+		}
+	}
+	return s
+}
+
+func testDomain() core.Domain {
+	dc := core.Computer{
+		DN:               "CN=DC1,OU=Domain Controllers,DC=example,DC=com",
+		Host:             "DC1.example.com",
+		Site:             "HQ",
+		OperatingSystem:  "Windows Server 2016 Standard",
+		LastLogon:        time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC),
+		DomainController: true,
+	}
+	fs := core.Computer{
+		DN:   "CN=FS1,OU=Servers,DC=example,DC=com",
+		Host: "FS1.example.com",
+		Site: "Branch",
+	}
+	return core.Domain{
+		NamingContext: core.NamingContext{
+			ID:   ole.NewGUID("{6B29FC40-CA47-1067-B31D-00DD010662DA}"),
+			DN:   "DC=example,DC=com",
+			Path: "example.com",
+		},
+		Groups: []core.Group{
+			{
+				Name:     "Data",
+				ID:       ole.NewGUID("{1B4E28BA-2FA1-11D2-883F-0016D3CCA427}"),
+				Domain:   "example.com",
+				Type:     core.GroupTypeData,
+				Folders:  []core.Folder{{Name: "Share", ID: ole.NewGUID("{3F2504E0-4F89-11D3-9A0C-0305E82C3301}")}},
+				Schedule: testSchedule(),
+				Members: []core.Member{
+					{
+						MemberInfo: core.MemberInfo{Name: "FS1", DN: "CN=FS1,CN=Topology,CN=Data", Computer: fs},
+						Connections: []core.Connection{
+							{Name: "DC1", Enabled: true, Computer: dc, Schedule: testSchedule(), RDC: true},
+						},
+					},
+				},
+				Err: errors.New("Unable to retrieve the subscriptions of FS1."),
+			},
+		},
+		Sites:    []core.Site{{Name: "HQ"}, {Name: "Branch"}},
+		SitesErr: api.NewError(api.E_RPC_SERVER_UNAVAILABLE),
+		Sysvol: &core.Sysvol{
+			State: core.SysvolEliminated,
+			DomainControllers: []core.DomainController{
+				{Computer: dc, State: core.SysvolEliminated, Member: "DC1"},
+			},
+		},
+		ConfigDuration: 1500 * time.Millisecond,
+	}
+}
+
+func testBacklog() core.Backlog {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	return core.Backlog{
+		Group: &core.Group{
+			Name: "Data",
+			ID:   ole.NewGUID("{1B4E28BA-2FA1-11D2-883F-0016D3CCA427}"),
+		},
+		From:         "FS1",
+		To:           "FS2",
+		FromComputer: core.Computer{Host: "FS1.example.com", Site: "Branch"},
+		ToComputer:   core.Computer{Host: "FS2.example.com", Site: "HQ"},
+		Folders: []core.FolderBacklog{
+			{Folder: &core.Folder{Name: "Share", ID: ole.NewGUID("{3F2504E0-4F89-11D3-9A0C-0305E82C3301}")}, Backlog: 12},
+			{Folder: &core.Folder{Name: "Archive"}, Backlog: -1},
+		},
+		Unscheduled: true,
+		Call: callstat.Call{
+			Description: "Backlog FS1 to FS2",
+			Start:       start,
+			End:         start.Add(2 * time.Second),
+			Err:         context.DeadlineExceeded,
+		},
+		Err: api.NewError(api.E_ACCESSDENIED),
+	}
+}
+
+// checkErr compares a decoded error with the original and clears both.
+func checkErr(t *testing.T, field string, got, want *error, sentinel error) {
+	t.Helper()
+	switch {
+	case *want == nil && *got != nil:
+		t.Errorf("%s: unexpected error %v", field, *got)
+	case *want != nil && *got == nil:
+		t.Errorf("%s: expected error %q, received nil", field, (*want).Error())
+	case *want != nil && (*got).Error() != (*want).Error():
+		t.Errorf("%s: expected error %q, received %q", field, (*want).Error(), (*got).Error())
+	case sentinel != nil && !errors.Is(*got, sentinel):
+		t.Errorf("%s: decoded error %v does not match %v", field, *got, sentinel)
+	}
+	*got, *want = nil, nil
+}
+
+func TestDomainEncoding(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.Name, func(t *testing.T) {
+			want := testDomain()
+			b, err := codec.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got core.Domain
+			if err := codec.Unmarshal(b, &got); err != nil {
+				t.Fatalf("%v\n%s", err, b)
+			}
+			checkErr(t, "SitesErr", &got.SitesErr, &want.SitesErr, api.ErrUnavailable)
+			if len(got.Groups) == len(want.Groups) {
+				checkErr(t, "Group.Err", &got.Groups[0].Err, &want.Groups[0].Err, nil)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("domain does not survive a round trip:\n%s", b)
+			}
+		})
+	}
+}
+
+func TestBacklogEncoding(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.Name, func(t *testing.T) {
+			want := testBacklog()
+			b, err := codec.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got core.Backlog
+			if err := codec.Unmarshal(b, &got); err != nil {
+				t.Fatalf("%v\n%s", err, b)
+			}
+			checkErr(t, "Err", &got.Err, &want.Err, api.ErrAccessDenied)
+			checkErr(t, "Call.Err", &got.Call.Err, &want.Call.Err, context.DeadlineExceeded)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("backlog does not survive a round trip:\n%s", b)
+			}
+		})
+	}
+}
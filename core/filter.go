@@ -0,0 +1,116 @@
+package core
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// privateDir is the name of the directory in the root of every replicated
+// folder that holds the member's staging and conflict areas. It is never
+// replicated.
+const privateDir = "DfsrPrivate"
+
+// Filter is a list of comma-separated wildcard patterns in the format of the
+// msDFSR-FileFilter and msDFSR-DirectoryFilter attributes, such as
+// "~*, *.bak, *.tmp". An asterisk matches any sequence of characters and a
+// question mark matches a single character. Patterns are matched without
+// regard to case.
+type Filter string
+
+// Patterns returns the individual patterns of the filter.
+func (f Filter) Patterns() (patterns []string) {
+	for _, pattern := range strings.Split(string(f), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return
+}
+
+// Match reports whether the given file or directory name matches any of the
+// patterns of the filter.
+func (f Filter) Match(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range f.Patterns() {
+		if wildcard(strings.ToLower(pattern), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded reports whether the file or directory at the given path, relative
+// to the root of the replicated folder, is excluded from replication by the
+// folder's filters. Both forward slashes and backslashes are accepted as
+// separators. A path that ends with a separator is treated as a directory,
+// otherwise its last element is treated as a file.
+//
+// A path is excluded if any of its directories matches the directory filter,
+// if it is a file that matches the file filter, or if it is within the
+// DfsrPrivate directory. Files that are excluded because of their attributes,
+// such as temporary files, cannot be determined from the path alone.
+func (f *Folder) Excluded(path string) bool {
+	path = strings.Replace(path, "\\", "/", -1)
+	isDir := strings.HasSuffix(path, "/")
+
+	var elements []string
+	for _, element := range strings.Split(path, "/") {
+		if element != "" && element != "." {
+			elements = append(elements, element)
+		}
+	}
+	if len(elements) == 0 {
+		return false
+	}
+
+	if strings.EqualFold(elements[0], privateDir) {
+		return true
+	}
+
+	dirs := elements
+	if !isDir {
+		dirs = elements[:len(elements)-1]
+		if f.FileFilter.Match(elements[len(elements)-1]) {
+			return true
+		}
+	}
+	for _, dir := range dirs {
+		if f.DirectoryFilter.Match(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcard reports whether name matches pattern, in which an asterisk matches
+// any sequence of characters and a question mark matches a single character.
+func wildcard(pattern, name string) bool {
+	// The most recent asterisk and the position in name that it was last
+	// matched up to, used for backtracking
+	star, match := -1, 0
+	p, n := 0, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, match = p, n
+			p++
+		case p < len(pattern) && pattern[p] == '?':
+			_, size := utf8.DecodeRuneInString(name[n:])
+			p++
+			n += size
+		case p < len(pattern) && pattern[p] == name[n]:
+			p++
+			n++
+		case star >= 0:
+			_, size := utf8.DecodeRuneInString(name[match:])
+			match += size
+			p, n = star+1, match
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
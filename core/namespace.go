@@ -0,0 +1,232 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/go-ole/go-ole"
+)
+
+// Namespace represents a domain-based DFS namespace.
+type Namespace struct {
+	Name    string
+	ID      *ole.GUID
+	Path    string // Namespace path, such as \\example.com\Public
+	Comment string
+	Targets []Target // Root targets of the namespace
+	Links   []Link
+}
+
+// Link represents a folder with targets in a DFS namespace.
+type Link struct {
+	Name    string // Path of the folder relative to the namespace root, such as Apps\Tools
+	ID      *ole.GUID
+	Path    string // Namespace path of the folder, such as \\example.com\Public\Apps\Tools
+	Comment string
+	Targets []Target
+}
+
+// Target represents a folder target of a DFS namespace, which is a shared
+// folder on a server.
+type Target struct {
+	Path   string `json:"path" yaml:"path"`                         // UNC path of the target, such as \\fs1\Tools
+	Online bool   `json:"online,omitempty" yaml:"online,omitempty"` // The target is referred to clients
+}
+
+// Server returns the name of the server that hosts the target.
+func (t *Target) Server() string {
+	server, _ := splitUNC(t.Path)
+	return server
+}
+
+// Share returns the path of the target on its server, starting with the
+// share name.
+func (t *Target) Share() string {
+	_, share := splitUNC(t.Path)
+	return share
+}
+
+// Resolution describes the replicated folder that serves a namespace path.
+type Resolution struct {
+	Namespace *Namespace
+	Link      *Link    // The link that contains the path, nil if the path is not within a link
+	Remainder string   // Remainder of the path beneath the link
+	Group     *Group   // Replication group of the folder, nil if the link is not replicated
+	Folder    *Folder  // Replicated folder, nil if the link is not replicated
+	Members   []Member // Members of the group that are targets of the link
+}
+
+// Resolve maps a namespace path, such as \\example.com\Public\Apps\Tools\a.txt,
+// to the namespace link that contains it and the replicated folder that
+// serves that link. It returns nil if the path is not within any of the
+// domain's namespaces.
+//
+// The server portion of the path is compared to the domain name of each
+// namespace and to its first label, which is usually the NetBIOS name of the
+// domain. Forward slashes are accepted as separators.
+//
+// A link is served by the replicated folder whose namespace path matches the
+// link. Folders that are not published in the namespace are matched by the
+// share name of the link's targets and by members that host its targets.
+func (d *Domain) Resolve(path string) *Resolution {
+	server, rest := splitUNC(path)
+	if server == "" {
+		return nil
+	}
+
+	for n := range d.Namespaces {
+		ns := &d.Namespaces[n]
+		nsServer, nsRest := splitUNC(ns.Path)
+		if !sameServer(server, nsServer) {
+			continue
+		}
+		remainder, ok := trimPath(rest, nsRest)
+		if !ok {
+			continue
+		}
+
+		r := &Resolution{Namespace: ns, Remainder: remainder}
+
+		// Find the deepest link that contains the path
+		for l := range ns.Links {
+			link := &ns.Links[l]
+			linkRemainder, ok := trimPath(remainder, link.Name)
+			if !ok {
+				continue
+			}
+			if r.Link == nil || len(link.Name) > len(r.Link.Name) {
+				r.Link, r.Remainder = link, linkRemainder
+			}
+		}
+
+		if r.Link != nil {
+			r.Group, r.Folder, r.Members = d.Replicas(r.Link)
+		}
+		return r
+	}
+
+	return nil
+}
+
+// Replicas returns the replicated folder that serves the given namespace link
+// and the members of its group that are targets of the link. It returns nil
+// if the link is not served by a replicated folder.
+func (d *Domain) Replicas(link *Link) (group *Group, folder *Folder, members []Member) {
+	// Folders that are published in the namespace refer to the link
+	for g := range d.Groups {
+		for f := range d.Groups[g].Folders {
+			if samePath(d.Groups[g].Folders[f].DfsPath, link.Path) {
+				group, folder = &d.Groups[g], &d.Groups[g].Folders[f]
+				return group, folder, targetMembers(group, link)
+			}
+		}
+	}
+
+	// Otherwise look for a folder named after the share of a target in a
+	// group that has the target's server as a member
+	for t := range link.Targets {
+		share := link.Targets[t].Share()
+		if i := strings.IndexAny(share, `\/`); i >= 0 {
+			share = share[:i]
+		}
+		server := link.Targets[t].Server()
+		for g := range d.Groups {
+			candidate := &d.Groups[g]
+			if !hasMember(candidate, server) {
+				continue
+			}
+			for f := range candidate.Folders {
+				if strings.EqualFold(candidate.Folders[f].Name, share) {
+					return candidate, &candidate.Folders[f], targetMembers(candidate, link)
+				}
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// targetMembers returns the members of the group that host a target of the
+// link.
+func targetMembers(group *Group, link *Link) (members []Member) {
+	for m := range group.Members {
+		for t := range link.Targets {
+			if sameServer(link.Targets[t].Server(), group.Members[m].Computer.Host) {
+				members = append(members, group.Members[m])
+				break
+			}
+		}
+	}
+	return
+}
+
+// hasMember reports whether the group has a member on the given server.
+func hasMember(group *Group, server string) bool {
+	for m := range group.Members {
+		if sameServer(server, group.Members[m].Computer.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitUNC splits a UNC path into its server and the remainder of the path.
+func splitUNC(path string) (server, rest string) {
+	path = strings.Replace(path, "/", `\`, -1)
+	path = strings.TrimLeft(path, `\`)
+	if i := strings.Index(path, `\`); i >= 0 {
+		return path[:i], strings.Trim(path[i+1:], `\`)
+	}
+	return path, ""
+}
+
+// trimPath returns the remainder of path beneath prefix, comparing path
+// elements without regard to case. The paths must use backslashes as
+// separators.
+func trimPath(path, prefix string) (remainder string, ok bool) {
+	prefix = strings.Trim(strings.Replace(prefix, "/", `\`, -1), `\`)
+	if prefix == "" {
+		return path, true
+	}
+	if len(path) < len(prefix) || !strings.EqualFold(path[:len(prefix)], prefix) {
+		return "", false
+	}
+	remainder = path[len(prefix):]
+	if remainder != "" && remainder[0] != '\\' {
+		return "", false // Only a partial match of the last element
+	}
+	return strings.TrimLeft(remainder, `\`), true
+}
+
+// samePath reports whether two UNC paths refer to the same location.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	serverA, restA := splitUNC(a)
+	serverB, restB := splitUNC(b)
+	return sameServer(serverA, serverB) && strings.EqualFold(restA, restB)
+}
+
+// sameServer reports whether two server or domain names refer to the same
+// host. A single label name is considered equal to a fully qualified name
+// with the same first label.
+func sameServer(a, b string) bool {
+	a, b = strings.TrimSuffix(a, "."), strings.TrimSuffix(b, ".")
+	if a == "" || b == "" {
+		return false
+	}
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	if strings.Contains(a, ".") && strings.Contains(b, ".") {
+		return false
+	}
+	return strings.EqualFold(firstLabel(a), firstLabel(b))
+}
+
+func firstLabel(name string) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
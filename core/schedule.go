@@ -0,0 +1,168 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ScheduleIntervals is the number of quarter-hour intervals in a weekly
+	// replication schedule.
+	ScheduleIntervals = 7 * 24 * 4
+
+	// ScheduleSize is the size in bytes of an msDFSR-Schedule value, which
+	// stores the bandwidth level of each interval in four bits.
+	ScheduleSize = ScheduleIntervals / 2
+
+	scheduleInterval = 15 * time.Minute
+)
+
+// ErrInvalidSchedule is returned when a replication schedule cannot be
+// decoded.
+var ErrInvalidSchedule = errors.New("The replication schedule is invalid.")
+
+// Bandwidth is the bandwidth level of a replication schedule interval. Levels
+// between BandwidthNone and BandwidthFull limit replication to a bandwidth
+// that doubles with each level, starting at 16 Kbps.
+type Bandwidth uint8
+
+// Bandwidth levels.
+const (
+	BandwidthNone Bandwidth = 0x0 // Replication is not allowed
+	BandwidthFull Bandwidth = 0xF // Replication is not limited
+)
+
+// Allowed reports whether replication is allowed at the bandwidth level.
+func (b Bandwidth) Allowed() bool {
+	return b != BandwidthNone
+}
+
+// Kbps returns the bandwidth limit in kilobits per second. It returns zero for
+// BandwidthNone and BandwidthFull.
+func (b Bandwidth) Kbps() uint {
+	if b == BandwidthNone || b >= BandwidthFull {
+		return 0
+	}
+	return 16 << (b - 1)
+}
+
+// String returns a string representation of the bandwidth level.
+func (b Bandwidth) String() string {
+	switch {
+	case b == BandwidthNone:
+		return "none"
+	case b >= BandwidthFull:
+		return "full"
+	case b.Kbps() >= 1024:
+		return fmt.Sprintf("%d Mbps", b.Kbps()/1024)
+	default:
+		return fmt.Sprintf("%d Kbps", b.Kbps())
+	}
+}
+
+// Schedule is a weekly replication schedule. It holds the bandwidth level of
+// each quarter-hour of the week, starting on Sunday at midnight.
+//
+// A nil schedule allows replication at full bandwidth at all times.
+type Schedule struct {
+	Intervals [ScheduleIntervals]Bandwidth
+	Local     bool // Intervals are in the local time of the receiving member instead of UTC
+}
+
+// ParseSchedule decodes an msDFSR-Schedule value. Each byte holds two
+// intervals, the earlier of which is stored in the high four bits.
+func ParseSchedule(data []byte) (*Schedule, error) {
+	if len(data) != ScheduleSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, received %d", ErrInvalidSchedule, ScheduleSize, len(data))
+	}
+	s := new(Schedule)
+	for i, b := range data {
+		s.Intervals[i*2] = Bandwidth(b >> 4)
+		s.Intervals[i*2+1] = Bandwidth(b & 0x0F)
+	}
+	return s, nil
+}
+
+// Bytes returns the msDFSR-Schedule encoding of the schedule.
+func (s *Schedule) Bytes() []byte {
+	data := make([]byte, ScheduleSize)
+	for i := range data {
+		data[i] = byte(s.Intervals[i*2]&0x0F)<<4 | byte(s.Intervals[i*2+1]&0x0F)
+	}
+	return data
+}
+
+// Bandwidth returns the bandwidth level of the schedule at time t. If the
+// schedule is in UTC t is converted to UTC, otherwise t is interpreted in its
+// own location, which should be that of the receiving member.
+func (s *Schedule) Bandwidth(t time.Time) Bandwidth {
+	if s == nil {
+		return BandwidthFull
+	}
+	return s.Intervals[s.index(t)]
+}
+
+// Allowed reports whether the schedule allows replication at time t.
+func (s *Schedule) Allowed(t time.Time) bool {
+	return s.Bandwidth(t).Allowed()
+}
+
+// Always reports whether the schedule allows replication at full bandwidth at
+// all times.
+func (s *Schedule) Always() bool {
+	if s == nil {
+		return true
+	}
+	for _, b := range s.Intervals {
+		if b != BandwidthFull {
+			return false
+		}
+	}
+	return true
+}
+
+// Never reports whether the schedule never allows replication.
+func (s *Schedule) Never() bool {
+	if s == nil {
+		return false
+	}
+	for _, b := range s.Intervals {
+		if b.Allowed() {
+			return false
+		}
+	}
+	return true
+}
+
+// NextAllowed returns the start of the first interval at or after time t in
+// which replication is allowed. If replication is allowed at time t then t
+// is returned. If the schedule never allows replication ok is false.
+func (s *Schedule) NextAllowed(t time.Time) (next time.Time, ok bool) {
+	if s.Allowed(t) {
+		return t, true
+	}
+	next = s.zone(t)
+	next = next.Add(-time.Duration(next.Minute()%15)*time.Minute - time.Duration(next.Second())*time.Second - time.Duration(next.Nanosecond()))
+	for i := 1; i <= ScheduleIntervals; i++ {
+		next = next.Add(scheduleInterval)
+		if s.Allowed(next) {
+			return next.In(t.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// index returns the interval that contains time t.
+func (s *Schedule) index(t time.Time) int {
+	t = s.zone(t)
+	return int(t.Weekday())*24*4 + t.Hour()*4 + t.Minute()/15
+}
+
+// zone returns t in the time zone of the schedule.
+func (s *Schedule) zone(t time.Time) time.Time {
+	if s.Local {
+		return t
+	}
+	return t.UTC()
+}
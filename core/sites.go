@@ -0,0 +1,65 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// SitePair identifies the direction of replication between two sites.
+type SitePair struct {
+	From string
+	To   string
+}
+
+// String returns a string representation of the site pair.
+func (p SitePair) String() string {
+	return p.From + " -> " + p.To
+}
+
+// CrossSite reports whether the pair replicates between different sites. It
+// returns false if either site is unknown.
+func (p SitePair) CrossSite() bool {
+	return p.From != "" && p.To != "" && !strings.EqualFold(p.From, p.To)
+}
+
+// SiteBacklog is the combined backlog of all connections between a pair of
+// sites.
+type SiteBacklog struct {
+	SitePair
+	Connections int  // Number of connections included in the rollup
+	Failed      int  // Number of connections whose backlog query failed
+	Backlog     uint // Sum of the backlogs of all successful connections
+}
+
+// SiteBacklogs rolls up the given backlogs by site pair. Backlogs without
+// site information are combined under a pair with empty site names. The
+// returned rollups are sorted by source and destination site.
+func SiteBacklogs(backlogs []*Backlog) []SiteBacklog {
+	index := make(map[SitePair]int)
+	var rollups []SiteBacklog
+	for _, backlog := range backlogs {
+		if backlog == nil {
+			continue
+		}
+		pair := backlog.Sites()
+		i, ok := index[pair]
+		if !ok {
+			i = len(rollups)
+			index[pair] = i
+			rollups = append(rollups, SiteBacklog{SitePair: pair})
+		}
+		rollups[i].Connections++
+		if backlog.Err != nil {
+			rollups[i].Failed++
+			continue
+		}
+		rollups[i].Backlog += backlog.Sum()
+	}
+	sort.Slice(rollups, func(i, j int) bool {
+		if rollups[i].From != rollups[j].From {
+			return rollups[i].From < rollups[j].From
+		}
+		return rollups[i].To < rollups[j].To
+	})
+	return rollups
+}
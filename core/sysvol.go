@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SysvolState is a state of the migration of SYSVOL replication from FRS to
+// DFSR, as set by dfsrmig.exe.
+type SysvolState int
+
+// SYSVOL migration states. Domain controllers report other values while they
+// move from one state to the next.
+const (
+	SysvolStart      SysvolState = 0 // FRS replicates and serves SYSVOL
+	SysvolPrepared   SysvolState = 1 // DFSR replicates a copy of SYSVOL, FRS still serves it
+	SysvolRedirected SysvolState = 2 // The copy replicated by DFSR is served, FRS still replicates the original
+	SysvolEliminated SysvolState = 3 // DFSR replicates and serves SYSVOL, FRS is no longer used
+)
+
+// String returns a string representation of the migration state.
+func (s SysvolState) String() string {
+	switch s {
+	case SysvolStart:
+		return "start"
+	case SysvolPrepared:
+		return "prepared"
+	case SysvolRedirected:
+		return "redirected"
+	case SysvolEliminated:
+		return "eliminated"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// MarshalText returns the string representation of the migration state.
+func (s SysvolState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a migration state from its string representation.
+// Transitional states are represented by their numeric value.
+func (s *SysvolState) UnmarshalText(text []byte) error {
+	for _, candidate := range []SysvolState{SysvolStart, SysvolPrepared, SysvolRedirected, SysvolEliminated} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*s = candidate
+			return nil
+		}
+	}
+	value, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("unknown SYSVOL migration state \"%s\"", text)
+	}
+	*s = SysvolState(value)
+	return nil
+}
+
+// DFSR reports whether DFSR replicates SYSVOL in the state.
+func (s SysvolState) DFSR() bool {
+	return s == SysvolPrepared || s == SysvolRedirected || s == SysvolEliminated
+}
+
+// Sysvol describes the replication of a domain's SYSVOL share.
+type Sysvol struct {
+	State             SysvolState // Global migration state of the domain
+	DomainControllers []DomainController
+}
+
+// DomainController represents a domain controller of a domain and its part in
+// SYSVOL replication.
+type DomainController struct {
+	Computer Computer
+	ReadOnly bool        // The domain controller is a read-only domain controller
+	State    SysvolState // Local migration state reported by the domain controller
+	Member   string      // Name of the domain controller's member of the SYSVOL group, empty if it is not a member
+}
+
+// DomainController returns the domain controller with the given computer
+// distinguished name, or nil if there is no such domain controller.
+func (s *Sysvol) DomainController(computerDN string) *DomainController {
+	if computerDN == "" {
+		return nil
+	}
+	for i := range s.DomainControllers {
+		if strings.EqualFold(s.DomainControllers[i].Computer.DN, computerDN) {
+			return &s.DomainControllers[i]
+		}
+	}
+	return nil
+}
+
+// SysvolGroup returns the replication group that replicates the domain's
+// SYSVOL share, or nil if there is none.
+func (d *Domain) SysvolGroup() *Group {
+	for g := range d.Groups {
+		if d.Groups[g].Sysvol() {
+			return &d.Groups[g]
+		}
+	}
+	return nil
+}
+
+// SysvolHealth is the result of analyzing the replication of a domain's
+// SYSVOL share. Domain controllers and members are identified by host name,
+// or by name if the host name is unknown.
+//
+// Because SYSVOL holds Group Policy, any backlog or failure reported here
+// means that domain controllers may apply different policies.
+type SysvolHealth struct {
+	State                SysvolState
+	Group                string     // Name of the SYSVOL replication group, empty if there is none
+	Migrating            []string   // Domain controllers that have not reached the global migration state
+	NotMembers           []string   // Domain controllers that are not members of the SYSVOL group
+	NotDomainControllers []string   // Members of the SYSVOL group that are not domain controllers
+	NoInbound            []string   // Members without enabled inbound connections, which never receive changes
+	Disabled             []string   // Members whose SYSVOL subscription is disabled
+	WritableRODCs        []string   // Read-only domain controllers whose SYSVOL subscription is writable
+	Backlogs             []*Backlog // SYSVOL backlogs that are not zero or could not be queried
+}
+
+// OK reports whether the analysis found no problems.
+func (h *SysvolHealth) OK() bool {
+	if h.State.DFSR() && h.Group == "" {
+		return false
+	}
+	return len(h.Migrating) == 0 && len(h.NotMembers) == 0 && len(h.NotDomainControllers) == 0 &&
+		len(h.NoInbound) == 0 && len(h.Disabled) == 0 && len(h.WritableRODCs) == 0 && len(h.Backlogs) == 0
+}
+
+// SysvolHealth analyzes the replication of the domain's SYSVOL share and the
+// given backlogs, which may include those of other groups. It requires the
+// SYSVOL information of the domain. If it is not present only the
+// configuration of the SYSVOL group is analyzed.
+//
+// Domain controllers are not expected to be members of the SYSVOL group
+// until the migration to DFSR has been prepared.
+func (d *Domain) SysvolHealth(backlogs []*Backlog) (health SysvolHealth) {
+	group := d.SysvolGroup()
+	if d.Sysvol != nil {
+		health.State = d.Sysvol.State
+	}
+	if group != nil {
+		health.Group = group.Name
+	}
+
+	if d.Sysvol != nil {
+		for i := range d.Sysvol.DomainControllers {
+			dc := &d.Sysvol.DomainControllers[i]
+			name := computerName(dc.Computer, dc.Member)
+			if dc.State != d.Sysvol.State {
+				health.Migrating = append(health.Migrating, name)
+			}
+			if dc.Member == "" && health.State.DFSR() && group != nil {
+				health.NotMembers = append(health.NotMembers, name)
+			}
+		}
+	}
+
+	if group != nil {
+		for m := range group.Members {
+			member := &group.Members[m]
+			name := computerName(member.Computer, member.Name)
+
+			var dc *DomainController
+			if d.Sysvol != nil {
+				dc = d.Sysvol.DomainController(member.Computer.DN)
+				if dc == nil {
+					health.NotDomainControllers = append(health.NotDomainControllers, name)
+				}
+			}
+
+			inbound := false
+			for c := range member.Connections {
+				if member.Connections[c].Enabled {
+					inbound = true
+					break
+				}
+			}
+			if !inbound && len(group.Members) > 1 {
+				health.NoInbound = append(health.NoInbound, name)
+			}
+
+			disabled, writable := false, false
+			for s := range member.Subscriptions {
+				disabled = disabled || !member.Subscriptions[s].Enabled
+				writable = writable || !member.Subscriptions[s].ReadOnly
+			}
+			if disabled {
+				health.Disabled = append(health.Disabled, name)
+			}
+			if writable && dc != nil && dc.ReadOnly {
+				health.WritableRODCs = append(health.WritableRODCs, name)
+			}
+		}
+	}
+
+	for _, backlog := range backlogs {
+		if backlog.Group == nil || !backlog.Group.Sysvol() {
+			continue
+		}
+		if backlog.Err != nil || len(backlog.Folders) == 0 || backlog.Sum() > 0 {
+			health.Backlogs = append(health.Backlogs, backlog)
+		}
+	}
+
+	sort.Strings(health.Migrating)
+	sort.Strings(health.NotMembers)
+	sort.Strings(health.NotDomainControllers)
+	sort.Strings(health.NoInbound)
+	sort.Strings(health.Disabled)
+	sort.Strings(health.WritableRODCs)
+	sort.SliceStable(health.Backlogs, func(i, j int) bool {
+		return health.Backlogs[i].Sum() > health.Backlogs[j].Sum()
+	})
+
+	return
+}
+
+// computerName returns the host name of the computer, or the given name if
+// the host name is unknown.
+func computerName(computer Computer, name string) string {
+	if computer.Host != "" {
+		return computer.Host
+	}
+	if name != "" {
+		return name
+	}
+	return computer.DN
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/dfsr.v0/callstat"
@@ -21,38 +24,203 @@ type NamingContext struct {
 type Domain struct {
 	NamingContext
 	Groups         []Group
+	Sites          []Site
+	SiteLinks      []SiteLink
+	SitesErr       error         // Error encountered while retrieving sites, if any
+	Namespaces     []Namespace   // Domain-based DFS namespaces
+	NamespacesErr  error         // Error encountered while retrieving namespaces, if any
+	Sysvol         *Sysvol       // Replication of the domain's SYSVOL share, nil if unknown
+	SysvolErr      error         // Error encountered while retrieving the SYSVOL state, if any
 	ConfigDuration time.Duration // Time elapsed while retrieving configuration
 }
 
-/*
+// Failed returns the groups in d whose configuration could not be retrieved.
+func (d *Domain) Failed() (groups []*Group) {
+	for g := range d.Groups {
+		if d.Groups[g].Err != nil {
+			groups = append(groups, &d.Groups[g])
+		}
+	}
+	return
+}
+
+// SiteLink returns the site link with the lowest cost that connects sites a
+// and b, or nil if they are not linked.
+func (d *Domain) SiteLink(a, b string) (link *SiteLink) {
+	for l := range d.SiteLinks {
+		candidate := &d.SiteLinks[l]
+		if !candidate.Links(a, b) {
+			continue
+		}
+		if link == nil || candidate.Cost < link.Cost {
+			link = candidate
+		}
+	}
+	return
+}
+
 // Site represents an Active Directory site.
 type Site struct {
-	Name           string
-	ID             *ole.GUID
-	Members        []Member
-	ConfigDuration time.Duration // Time elapsed while retrieving configuration
+	Name    string
+	ID      *ole.GUID
+	DN      string   // Distinguished name
+	Subnets []string // Subnets assigned to the site in CIDR notation
+}
+
+// SiteLink represents an Active Directory IP site link.
+type SiteLink struct {
+	Name     string
+	ID       *ole.GUID
+	DN       string        // Distinguished name
+	Cost     int           // Relative cost of replication over the link
+	Interval time.Duration // Replication interval of Active Directory replication over the link
+	Sites    []string      // Names of the sites connected by the link
+}
+
+// Links reports whether the site link connects sites a and b.
+func (l *SiteLink) Links(a, b string) bool {
+	var foundA, foundB bool
+	for _, site := range l.Sites {
+		if strings.EqualFold(site, a) {
+			foundA = true
+		}
+		if strings.EqualFold(site, b) {
+			foundB = true
+		}
+	}
+	return foundA && foundB
 }
-*/
 
 // Group represents a replication group.
+//
+// If the group's configuration could not be retrieved Err will be non-nil. In
+// that case only the name and ID of the group are provided, and only if they
+// could be retrieved.
 type Group struct {
 	Name           string
 	ID             *ole.GUID
+	Domain         string // Distinguished name of the domain that holds the group
+	Type           GroupType
+	Description    string
+	Version        string // Version of the DFSR configuration format
+	Flags          int    // Value of the msDFSR-Flags attribute
+	Options        int    // Value of the msDFSR-Options attribute
 	Folders        []Folder
 	Members        []Member
+	Schedule       *Schedule     // Default schedule of the group's connections, nil if unrestricted
 	ConfigDuration time.Duration // Time elapsed while retrieving configuration
+	Err            error         // Error encountered while retrieving configuration
+}
+
+// GroupType is the type of a replication group.
+type GroupType int
+
+// Replication group types.
+const (
+	GroupTypeData   GroupType = 0 // Replicates user data
+	GroupTypeSysvol GroupType = 1 // Replicates the domain's SYSVOL share
+)
+
+// String returns a string representation of the group type.
+func (t GroupType) String() string {
+	switch t {
+	case GroupTypeData:
+		return "data"
+	case GroupTypeSysvol:
+		return "sysvol"
+	default:
+		return strconv.Itoa(int(t))
+	}
+}
+
+// MarshalText returns the string representation of the group type.
+func (t GroupType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses a group type from its string representation. Types
+// without a name are represented by their numeric value.
+func (t *GroupType) UnmarshalText(text []byte) error {
+	for _, candidate := range []GroupType{GroupTypeData, GroupTypeSysvol} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*t = candidate
+			return nil
+		}
+	}
+	value, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("unknown replication group type \"%s\"", text)
+	}
+	*t = GroupType(value)
+	return nil
+}
+
+// Sysvol reports whether the group replicates the domain's SYSVOL share.
+func (g *Group) Sysvol() bool {
+	return g.Type == GroupTypeSysvol
+}
+
+// ConnectionSchedule returns the schedule that applies to the given connection
+// within the group. A connection's own schedule overrides that of its group.
+// If neither is present nil is returned, which allows replication at all
+// times.
+func (g *Group) ConnectionSchedule(conn *Connection) *Schedule {
+	if conn.Schedule != nil {
+		return conn.Schedule
+	}
+	return g.Schedule
 }
 
 // Folder represents a replication folder.
 type Folder struct {
-	Name string
-	ID   *ole.GUID
+	Name            string
+	ID              *ole.GUID
+	Description     string
+	FileFilter      Filter // Files that are not replicated
+	DirectoryFilter Filter // Directories that are not replicated
+	DfsPath         string // Path of the folder in a DFS namespace, if it is published
 }
 
 // Member represents a replication member.
 type Member struct {
 	MemberInfo
-	Connections []Connection
+	Connections   []Connection
+	Subscriptions []Subscription // Local settings of the member's replicated folders
+}
+
+// Subscription returns the member's subscription to the replicated folder
+// with the given ID, or nil if the member has no such subscription.
+func (m *Member) Subscription(folderID *ole.GUID) *Subscription {
+	if folderID == nil {
+		return nil
+	}
+	for s := range m.Subscriptions {
+		if id := m.Subscriptions[s].Folder.ID; id != nil && ole.IsEqualGUID(id, folderID) {
+			return &m.Subscriptions[s]
+		}
+	}
+	return nil
+}
+
+// CrossSite reports whether the given inbound connection of the member
+// replicates between different sites. It returns false if the site of either
+// computer is unknown.
+func (m *Member) CrossSite(conn *Connection) bool {
+	return SitePair{From: conn.Computer.Site, To: m.Computer.Site}.CrossSite()
+}
+
+// Subscription represents the local settings of a replicated folder on a
+// member, which are stored beneath the member's computer object.
+type Subscription struct {
+	Folder        Folder // Replicated folder, which only has an ID if it is not part of the group
+	DN            string // Distinguished name
+	RootPath      string // Local path of the replicated folder
+	StagingPath   string
+	StagingQuota  int // Staging quota in megabytes
+	ConflictPath  string
+	ConflictQuota int  // Conflict and deleted quota in megabytes
+	ReadOnly      bool // Changes made on the member are not replicated
+	Enabled       bool // The member participates in replication of the folder
 }
 
 // MemberInfo represents identifying information about a replication member.
@@ -65,17 +233,37 @@ type MemberInfo struct {
 
 // Computer represents information about a computer.
 type Computer struct {
-	DN   string // Distinguished name
-	Host string
+	DN                     string // Distinguished name
+	Host                   string
+	Site                   string    // Name of the Active Directory site
+	OperatingSystem        string    // Such as "Windows Server 2016 Standard"
+	OperatingSystemVersion string    // Such as "10.0 (14393)"
+	LastLogon              time.Time // Replicated last logon time, which may lag by two weeks, zero if unknown
+	Disabled               bool      // The computer account is disabled
+	DomainController       bool      // The computer is a domain controller or a read-only domain controller
+}
+
+// Inactive reports whether the computer is known to have last logged on to the
+// domain before the given time. Computers that have been decommissioned
+// without removing them from the directory stop logging on. Because the
+// replicated last logon time lags, times less than two weeks ago should not
+// be used.
+func (c *Computer) Inactive(since time.Time) bool {
+	return !c.LastLogon.IsZero() && c.LastLogon.Before(since)
 }
 
 // Connection represents a one-way connection between replication members.
 type Connection struct {
-	Name     string
-	ID       *ole.GUID
-	MemberDN string
-	Enabled  bool
-	Computer Computer // Distinguished name of source member in topology, matches DN field of that Member
+	Name         string
+	ID           *ole.GUID
+	MemberDN     string
+	Enabled      bool
+	Computer     Computer  // Distinguished name of source member in topology, matches DN field of that Member
+	Schedule     *Schedule // Overrides the schedule of the group, nil if not overridden
+	RDC          bool      // Remote differential compression is used
+	CrossFileRDC bool      // Similar files are used as sources for remote differential compression
+	Keywords     string
+	Options      int // Value of the msDFSR-Options attribute
 }
 
 // FolderBacklog represents the backlog for an individual folder.
@@ -86,12 +274,15 @@ type FolderBacklog struct {
 
 // Backlog represents the backlog from one DFSR member to another.
 type Backlog struct {
-	Group   *Group
-	From    string
-	To      string
-	Folders []FolderBacklog
-	Call    callstat.Call
-	Err     error
+	Group        *Group
+	From         string
+	To           string
+	FromComputer Computer // Source computer, if known
+	ToComputer   Computer // Destination computer, if known
+	Folders      []FolderBacklog
+	Unscheduled  bool // The connection's UTC schedule did not allow replication when queried
+	Call         callstat.Call
+	Err          error
 }
 
 // Sum returns the total backlog of all replicated folders. Negatives values,
@@ -105,6 +296,11 @@ func (b *Backlog) Sum() (backlog uint) {
 	return
 }
 
+// Sites returns the source and destination sites of the backlog.
+func (b *Backlog) Sites() SitePair {
+	return SitePair{From: b.FromComputer.Site, To: b.ToComputer.Site}
+}
+
 // IsZero reports whether b represents a successful backlog query that returned
 // a count of zero for all replication folders in the replication group.
 func (b *Backlog) IsZero() bool {
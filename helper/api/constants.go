@@ -2,12 +2,34 @@ package api
 
 import "errors"
 
+// HRESULT values that are classified by Classify.
 const (
-	E_INVALID_NAMESPACE = 2147749902
-	E_ACCESS_DENIED     = 2147749891
+	E_INVALID_NAMESPACE = 2147749902 // WBEM_E_INVALID_NAMESPACE
+	E_ACCESS_DENIED     = 2147749891 // WBEM_E_ACCESS_DENIED
+	E_ACCESSDENIED      = 0x80070005
+
+	E_RPC_SERVER_UNAVAILABLE = 0x800706BA // HRESULT_FROM_WIN32(RPC_S_SERVER_UNAVAILABLE)
+	E_RPC_SERVER_TOO_BUSY    = 0x800706BB // HRESULT_FROM_WIN32(RPC_S_SERVER_TOO_BUSY)
+	E_RPC_CALL_FAILED        = 0x800706BE // HRESULT_FROM_WIN32(RPC_S_CALL_FAILED)
+	E_RPC_CALL_FAILED_DNE    = 0x800706BF // HRESULT_FROM_WIN32(RPC_S_CALL_FAILED_DNE)
+	E_RPC_DISCONNECTED       = 0x80010108 // RPC_E_DISCONNECTED
+	E_RPC_SERVER_DIED_DNE    = 0x80010012 // RPC_E_SERVER_DIED_DNE
+
+	E_RPC_TIMEOUT   = 0x8001011F // RPC_E_TIMEOUT
+	E_WIN32_TIMEOUT = 0x800705B4 // HRESULT_FROM_WIN32(ERROR_TIMEOUT)
+
+	E_RPC_CALL_CANCELLED = 0x8007071A // HRESULT_FROM_WIN32(RPC_S_CALL_CANCELLED)
+	E_WIN32_CANCELLED    = 0x800704C7 // HRESULT_FROM_WIN32(ERROR_CANCELLED)
 )
 
+// Sentinel errors for each error category. Errors returned by this package
+// can be matched against them with errors.Is.
 var (
-	ErrInvalidNamespace = errors.New("The provided name or namespace is invalid.")
+	ErrUnavailable      = errors.New("The RPC server is unavailable.")
 	ErrAccessDenied     = errors.New("Access denied.")
+	ErrInvalidNamespace = errors.New("The provided name or namespace is invalid.")
+	ErrNotLocalAdmin    = errors.New("The caller is not a local administrator.")
+	ErrLDAP             = errors.New("The DFSR helper encountered an LDAP error.")
+	ErrTimeout          = errors.New("The remote procedure call timed out.")
+	ErrCanceled         = errors.New("The remote procedure call was cancelled.")
 )
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/callstat"
+)
+
+// Category classifies DFSR Helper protocol and RPC errors.
+type Category int
+
+// Error categories.
+const (
+	CategoryUnknown Category = iota
+	CategoryUnavailable
+	CategoryAccessDenied
+	CategoryInvalidNamespace
+	CategoryNotLocalAdmin
+	CategoryLDAP
+	CategoryTimeout
+	CategoryCanceled
+)
+
+// String returns a string representation of the category.
+func (c Category) String() string {
+	switch c {
+	case CategoryUnavailable:
+		return "unavailable"
+	case CategoryAccessDenied:
+		return "access denied"
+	case CategoryInvalidNamespace:
+		return "invalid namespace"
+	case CategoryNotLocalAdmin:
+		return "not local admin"
+	case CategoryLDAP:
+		return "ldap"
+	case CategoryTimeout:
+		return "timeout"
+	case CategoryCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// sentinel returns the sentinel error for the category, or nil if it does not
+// have one.
+func (c Category) sentinel() error {
+	switch c {
+	case CategoryUnavailable:
+		return ErrUnavailable
+	case CategoryAccessDenied:
+		return ErrAccessDenied
+	case CategoryInvalidNamespace:
+		return ErrInvalidNamespace
+	case CategoryNotLocalAdmin:
+		return ErrNotLocalAdmin
+	case CategoryLDAP:
+		return ErrLDAP
+	case CategoryTimeout:
+		return ErrTimeout
+	case CategoryCanceled:
+		return ErrCanceled
+	default:
+		return nil
+	}
+}
+
+// Error is an error returned by a DFSR Helper protocol call. It carries the
+// HRESULT returned by the call and its category.
+//
+// Errors can be matched against the sentinel error of their category with
+// errors.Is, which does not depend on the language of the system that
+// produced them:
+//
+//   if errors.Is(err, api.ErrUnavailable) {
+//     // The server is down
+//   }
+//
+// Errors in the timeout and cancellation categories also match
+// context.DeadlineExceeded and context.Canceled respectively.
+type Error struct {
+	HResult  uint32
+	Category Category
+	Err      error // Underlying error, typically an *ole.OleError
+}
+
+// NewError returns a classified error for the given HRESULT.
+func NewError(hr uintptr) *Error {
+	return &Error{
+		HResult:  uint32(hr),
+		Category: CategoryOf(hr),
+		Err:      ole.NewError(hr),
+	}
+}
+
+// Error returns the message of the underlying error. If the system was unable
+// to format a message for the HRESULT a description of its category is
+// returned instead.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		if msg := e.Err.Error(); msg != "" && !strings.Contains(msg, "FormatMessage failed") {
+			return msg
+		}
+	}
+	if s := e.Category.sentinel(); s != nil {
+		return s.Error()
+	}
+	return fmt.Sprintf("HRESULT 0x%08X", e.HResult)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error for the category of e.
+func (e *Error) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+	if target == e.Category.sentinel() {
+		return true
+	}
+	switch e.Category {
+	case CategoryTimeout:
+		return target == context.DeadlineExceeded
+	case CategoryCanceled:
+		return target == context.Canceled
+	}
+	return false
+}
+
+// LDAPCode returns the LDAP result code of errors in the LDAP category.
+func (e *Error) LDAPCode() (code int, ok bool) {
+	if e.Category != CategoryLDAP {
+		return 0, false
+	}
+	return int(e.HResult - uint32(dfsrHelperLdapErrorBase)), true
+}
+
+// CategoryOf returns the category of the given HRESULT.
+func CategoryOf(hr uintptr) Category {
+	switch hr {
+	case E_RPC_SERVER_UNAVAILABLE, E_RPC_SERVER_TOO_BUSY, E_RPC_CALL_FAILED, E_RPC_CALL_FAILED_DNE, E_RPC_DISCONNECTED, E_RPC_SERVER_DIED_DNE:
+		return CategoryUnavailable
+	case E_ACCESS_DENIED, E_ACCESSDENIED:
+		return CategoryAccessDenied
+	case E_INVALID_NAMESPACE:
+		return CategoryInvalidNamespace
+	case uintptr(dfsrHelperErrorNotLocalAdmin):
+		return CategoryNotLocalAdmin
+	case E_RPC_TIMEOUT, E_WIN32_TIMEOUT:
+		return CategoryTimeout
+	case E_RPC_CALL_CANCELLED, E_WIN32_CANCELLED:
+		return CategoryCanceled
+	}
+	if hr >= uintptr(dfsrHelperLdapErrorBase) && hr < uintptr(dfsrHelperLdapErrorBase)+0x1000 {
+		return CategoryLDAP
+	}
+	return CategoryUnknown
+}
+
+// Classify returns the category of err. It recognizes errors returned by this
+// package, COM errors and context errors anywhere in the error chain.
+func Classify(err error) Category {
+	if err == nil {
+		return CategoryUnknown
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Category
+	}
+	var oe *ole.OleError
+	if errors.As(err, &oe) {
+		return CategoryOf(oe.Code())
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimeout
+	case errors.Is(err, context.Canceled):
+		return CategoryCanceled
+	}
+	return CategoryUnknown
+}
+
+func init() {
+	callstat.RegisterErrorCodec(callstat.ErrorCodec{
+		Encode: encodeError,
+		Decode: decodeError,
+	})
+}
+
+// encodeError returns the category and HRESULT of err for serialization.
+func encodeError(err error) (category string, hr uint32, coded bool) {
+	if c := Classify(err); c != CategoryUnknown {
+		category = c.String()
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return category, e.HResult, true
+	}
+	return category, 0, false
+}
+
+// decodeError reconstructs an error that was serialized with its HRESULT.
+func decodeError(hr uint32, message string) error {
+	return &Error{
+		HResult:  hr,
+		Category: CategoryOf(uintptr(hr)),
+		Err:      errors.New(message),
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-ole/go-ole"
+)
+
+var categoryTests = []struct {
+	hr       uintptr
+	category Category
+	sentinel error
+}{
+	{E_RPC_SERVER_UNAVAILABLE, CategoryUnavailable, ErrUnavailable},
+	{E_RPC_SERVER_TOO_BUSY, CategoryUnavailable, ErrUnavailable},
+	{E_RPC_CALL_FAILED, CategoryUnavailable, ErrUnavailable},
+	{E_RPC_CALL_FAILED_DNE, CategoryUnavailable, ErrUnavailable},
+	{E_RPC_DISCONNECTED, CategoryUnavailable, ErrUnavailable},
+	{E_RPC_SERVER_DIED_DNE, CategoryUnavailable, ErrUnavailable},
+	{E_ACCESS_DENIED, CategoryAccessDenied, ErrAccessDenied},
+	{E_ACCESSDENIED, CategoryAccessDenied, ErrAccessDenied},
+	{E_INVALID_NAMESPACE, CategoryInvalidNamespace, ErrInvalidNamespace},
+	{uintptr(dfsrHelperErrorNotLocalAdmin), CategoryNotLocalAdmin, ErrNotLocalAdmin},
+	{uintptr(dfsrHelperLdapErrorBase), CategoryLDAP, ErrLDAP},
+	{uintptr(dfsrHelperLdapErrorBase) + 0x31, CategoryLDAP, ErrLDAP},
+	{E_RPC_TIMEOUT, CategoryTimeout, ErrTimeout},
+	{E_WIN32_TIMEOUT, CategoryTimeout, ErrTimeout},
+	{E_RPC_CALL_CANCELLED, CategoryCanceled, ErrCanceled},
+	{E_WIN32_CANCELLED, CategoryCanceled, ErrCanceled},
+	{0x80004005, CategoryUnknown, nil}, // E_FAIL
+	{uintptr(dfsrHelperLdapErrorBase) + 0x1000, CategoryUnknown, nil},
+	{0, CategoryUnknown, nil},
+}
+
+var sentinels = []error{
+	ErrUnavailable,
+	ErrAccessDenied,
+	ErrInvalidNamespace,
+	ErrNotLocalAdmin,
+	ErrLDAP,
+	ErrTimeout,
+	ErrCanceled,
+}
+
+func TestCategoryOf(t *testing.T) {
+	for _, tt := range categoryTests {
+		if got := CategoryOf(tt.hr); got != tt.category {
+			t.Errorf("CategoryOf(0x%08X) = %s, want %s", tt.hr, got, tt.category)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	for _, tt := range categoryTests {
+		err := NewError(tt.hr)
+		if err.HResult != uint32(tt.hr) || err.Category != tt.category {
+			t.Errorf("NewError(0x%08X) = %08X %s, want %s", tt.hr, err.HResult, err.Category, tt.category)
+		}
+		for _, sentinel := range sentinels {
+			if want := sentinel == tt.sentinel; errors.Is(err, sentinel) != want {
+				t.Errorf("errors.Is(NewError(0x%08X), %v) = %t, want %t", tt.hr, sentinel, !want, want)
+			}
+		}
+		if errors.Is(err, nil) {
+			t.Errorf("errors.Is(NewError(0x%08X), nil) = true", tt.hr)
+		}
+		if err.Error() == "" {
+			t.Errorf("NewError(0x%08X) has an empty message", tt.hr)
+		}
+	}
+}
+
+func TestErrorContext(t *testing.T) {
+	tests := []struct {
+		hr       uintptr
+		deadline bool
+		canceled bool
+	}{
+		{E_RPC_TIMEOUT, true, false},
+		{E_RPC_CALL_CANCELLED, false, true},
+		{E_RPC_SERVER_UNAVAILABLE, false, false},
+	}
+	for _, tt := range tests {
+		err := NewError(tt.hr)
+		if got := errors.Is(err, context.DeadlineExceeded); got != tt.deadline {
+			t.Errorf("errors.Is(NewError(0x%08X), context.DeadlineExceeded) = %t, want %t", tt.hr, got, tt.deadline)
+		}
+		if got := errors.Is(err, context.Canceled); got != tt.canceled {
+			t.Errorf("errors.Is(NewError(0x%08X), context.Canceled) = %t, want %t", tt.hr, got, tt.canceled)
+		}
+	}
+}
+
+func TestErrorWrapped(t *testing.T) {
+	err := fmt.Errorf("backlog query for FS1: %w", NewError(E_RPC_SERVER_UNAVAILABLE))
+
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("errors.Is(%v, ErrUnavailable) = false", err)
+	}
+	if errors.Is(err, ErrAccessDenied) {
+		t.Errorf("errors.Is(%v, ErrAccessDenied) = true", err)
+	}
+
+	var e *Error
+	if !errors.As(err, &e) || e.HResult != E_RPC_SERVER_UNAVAILABLE {
+		t.Errorf("errors.As(%v) = %v", err, e)
+	}
+	var oe *ole.OleError
+	if !errors.As(err, &oe) || oe.Code() != E_RPC_SERVER_UNAVAILABLE {
+		t.Errorf("errors.As(%v) did not find the COM error", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Category
+	}{
+		{"nil", nil, CategoryUnknown},
+		{"error", NewError(E_ACCESS_DENIED), CategoryAccessDenied},
+		{"wrapped error", fmt.Errorf("call: %w", NewError(E_INVALID_NAMESPACE)), CategoryInvalidNamespace},
+		{"COM error", ole.NewError(E_RPC_SERVER_UNAVAILABLE), CategoryUnavailable},
+		{"wrapped COM error", fmt.Errorf("call: %w", ole.NewError(E_WIN32_TIMEOUT)), CategoryTimeout},
+		{"unknown COM error", ole.NewError(0x80004005), CategoryUnknown},
+		{"deadline", context.DeadlineExceeded, CategoryTimeout},
+		{"wrapped cancellation", fmt.Errorf("call: %w", context.Canceled), CategoryCanceled},
+		{"sentinel", ErrUnavailable, CategoryUnknown},
+		{"other", errors.New("other"), CategoryUnknown},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("%s: Classify(%v) = %s, want %s", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLDAPCode(t *testing.T) {
+	if code, ok := NewError(uintptr(dfsrHelperLdapErrorBase) + 0x31).LDAPCode(); !ok || code != 0x31 {
+		t.Errorf("LDAPCode = %d, %t, want 49, true", code, ok)
+	}
+	if _, ok := NewError(E_ACCESS_DENIED).LDAPCode(); ok {
+		t.Error("LDAPCode of a non-LDAP error succeeded")
+	}
+}
@@ -8,13 +8,13 @@ import "github.com/go-ole/go-ole"
 // component object model interface.
 //
 // In a typical use case, the provided clsid should be CLSID_DFSRHelper
-func NewIServerHealthReport2(server string, clsid *ole.GUID) (*IServerHealthReport, error) {
+func NewIServerHealthReport2(server string, clsid *ole.GUID) (*IServerHealthReport2, error) {
 	return nil, ole.NewError(ole.E_NOTIMPL)
 }
 
 // GetReport retrieves a report for the given replication group.
 //
 // [MS-DFSRH]: 3.1.5.4.5
-func (v *IServerHealthReport) GetReport(group ole.GUID, server string, referenceVectors ole.SafeArrayConversion, flags int32) (memberVectors ole.SafeArrayConversion, report string, err error) {
-	return ole.SafeArrayConversion{}, "", ole.NewError(ole.E_NOTIMPL)
+func (v *IServerHealthReport) GetReport(group ole.GUID, server string, referenceVectors *ole.SafeArrayConversion, flags int32) (memberVectors *ole.SafeArrayConversion, report string, err error) {
+	return nil, "", ole.NewError(ole.E_NOTIMPL)
 }
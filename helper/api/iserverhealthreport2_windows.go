@@ -16,7 +16,10 @@ import (
 // In a typical use case, the provided clsid should be CLSID_DFSRHelper
 func NewIServerHealthReport2(server string, clsid *ole.GUID) (*IServerHealthReport2, error) {
 	p, err := comutil.CreateRemoteObject(server, clsid, IID_IServerHealthReport2)
-	return (*IServerHealthReport2)(unsafe.Pointer(p)), err
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*IServerHealthReport2)(unsafe.Pointer(p)), nil
 }
 
 // GetReport retrieves a report for the given replication group.
@@ -16,7 +16,10 @@ import (
 // In a typical use case, the provided clsid should CLSID_DFSRHelper
 func NewIServerHealthReport(server string, clsid *ole.GUID) (*IServerHealthReport, error) {
 	p, err := comutil.CreateRemoteObject(server, clsid, IID_IServerHealthReport)
-	return (*IServerHealthReport)(unsafe.Pointer(p)), err
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*IServerHealthReport)(unsafe.Pointer(p)), nil
 }
 
 // GetReferenceVersionVectors retrieves the version vectors for the given
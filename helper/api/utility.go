@@ -1,23 +1,26 @@
 package api
 
 import (
-	"strings"
-
 	"github.com/go-ole/go-ole"
 )
 
 // convertHresultToError converts syscall to error, if call is unsuccessful.
 func convertHresultToError(hr uintptr) (err error) {
 	if hr != 0 {
-		err = ole.NewError(hr)
-		if strings.Contains(err.Error(), "FormatMessage failed") {
-			switch hr {
-			case E_INVALID_NAMESPACE:
-				err = ErrInvalidNamespace
-			case E_ACCESS_DENIED:
-				err = ErrAccessDenied
-			}
-		}
+		err = NewError(hr)
 	}
 	return
 }
+
+// convertError converts COM errors returned by other packages into classified
+// errors. Other errors are returned unmodified.
+func convertError(err error) error {
+	if oe, ok := err.(*ole.OleError); ok {
+		return &Error{
+			HResult:  uint32(oe.Code()),
+			Category: CategoryOf(oe.Code()),
+			Err:      oe,
+		}
+	}
+	return err
+}
@@ -28,6 +28,11 @@ var DefaultEndpointConfig = EndpointConfig{
 //
 // Limiting instructs the client to limit the maximum number of simultaneous
 // workers that can talk to an endpoint.
+//
+// Factory creates the underlying Reporter for each connection to an endpoint.
+// If it is nil NewReporter is used, which talks to the DFSR Helper protocol
+// server via DCOM. Changes to the factory take effect the next time an
+// endpoint reconnects.
 type EndpointConfig struct {
 	Caching                     bool
 	CacheDuration               time.Duration
@@ -35,6 +40,7 @@ type EndpointConfig struct {
 	Limit                       uint          // Maximum number of simultaneous calls
 	OnlineReconnectionInterval  time.Duration // Time between connection attempts when endpoint is online
 	OfflineReconnectionInterval time.Duration // Time between connection attempts when endpoint is offline
+	Factory                     ReporterFactory
 
 	// TODO: Use ICMP pings to assess network failure
 	//PingInterval  time.Duration
@@ -379,7 +385,12 @@ func (e *Endpoint) updateStateAfterCall(r Reporter, err error, when time.Time) {
 func createEndpointConnection(fqdn string, config EndpointConfig) (r Reporter, timestamp time.Time, err error) {
 	timestamp = time.Now()
 
-	r, err = NewReporter(fqdn)
+	factory := config.Factory
+	if factory == nil {
+		factory = NewReporter
+	}
+
+	r, err = factory(fqdn)
 	if err != nil {
 		return
 	}
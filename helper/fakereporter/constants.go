@@ -0,0 +1,15 @@
+package fakereporter
+
+import "errors"
+
+var (
+	// ErrUnknownMember is returned when a reporter is requested for a host
+	// that is not a member of the simulated domain.
+	ErrUnknownMember = errors.New("The requested server is not a member of the simulated domain.")
+	// ErrUnknownGroup is returned when a call references a replication group
+	// that does not exist in the simulated domain.
+	ErrUnknownGroup = errors.New("The requested replication group does not exist in the simulated domain.")
+	// ErrInvalidVector is returned when a vector that was not produced by the
+	// simulator is provided.
+	ErrInvalidVector = errors.New("The provided version vector was not produced by the simulator.")
+)
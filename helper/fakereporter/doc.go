@@ -0,0 +1,16 @@
+// Package fakereporter provides a scripted simulation of the DFSR members of
+// a domain.
+//
+// A Simulator is driven by a declarative Scenario that describes members,
+// replication groups, per-folder backlogs that evolve over time, injected
+// latency and injected failures. It produces helper.Reporter implementations
+// for the simulated members along with a matching core.Domain, so that the
+// helper and monitor packages can be exercised on systems that do not support
+// the DFSR Helper protocol:
+//
+//   sim, err := fakereporter.New(scenario)
+//   if err != nil {
+//     return err
+//   }
+//   mon := monitor.NewWithConfig(sim, time.Minute, 0, 1, monitor.Config{Factory: sim.NewReporter})
+package fakereporter
@@ -0,0 +1,113 @@
+package fakereporter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/callstat"
+	"gopkg.in/dfsr.v0/helper"
+	"gopkg.in/dfsr.v0/versionvector"
+)
+
+var _ = (helper.Reporter)((*reporter)(nil)) // Compile-time interface compliance check
+
+// reporter provides a simulated implementation of the Reporter interface for
+// a single member.
+type reporter struct {
+	sim    *Simulator
+	member *MemberSpec
+
+	m      sync.RWMutex
+	closed bool
+}
+
+// vectorValue is the value of the version vectors produced by the simulator.
+type vectorValue struct {
+	Group  ole.GUID
+	Member string // Name of the member that produced the vector
+}
+
+func (r *reporter) Close() {
+	r.m.Lock()
+	r.closed = true
+	r.m.Unlock()
+}
+
+func (r *reporter) Vector(ctx context.Context, group ole.GUID) (v *versionvector.Vector, call callstat.Call, err error) {
+	call.Begin("Reporter.Vector")
+	defer call.Complete(err)
+
+	spec, err := r.prepare(ctx, &group, OpVector)
+	if err != nil {
+		return
+	}
+	if !containsFold(spec.Members, r.member.Name) {
+		err = ErrUnknownGroup
+		return
+	}
+
+	v = versionvector.NewFromValue(vectorValue{Group: group, Member: r.member.Name})
+	return
+}
+
+func (r *reporter) Backlog(ctx context.Context, v *versionvector.Vector) (backlog []int, call callstat.Call, err error) {
+	call.Begin("Reporter.Backlog")
+	defer call.Complete(err)
+
+	ref, ok := v.Value().(vectorValue)
+	if !ok {
+		err = ErrInvalidVector
+		return
+	}
+
+	spec, err := r.prepare(ctx, &ref.Group, OpBacklog)
+	if err != nil {
+		return
+	}
+
+	backlog = make([]int, len(spec.Folders))
+	for i, folder := range spec.Folders {
+		backlog[i] = r.sim.backlog(spec.Name, folder, r.member.Name, ref.Member)
+	}
+	return
+}
+
+func (r *reporter) Report(ctx context.Context, group *ole.GUID, vector *versionvector.Vector, backlog, files bool) (data *ole.SafeArrayConversion, report string, call callstat.Call, err error) {
+	call.Begin("Reporter.Report")
+	defer call.Complete(err)
+
+	_, err = r.prepare(ctx, group, OpReport)
+	return
+}
+
+// prepare checks the state of the reporter, applies the member's latency and
+// returns the group spec for the call or the error that the call should fail
+// with.
+func (r *reporter) prepare(ctx context.Context, group *ole.GUID, op string) (spec *GroupSpec, err error) {
+	r.m.RLock()
+	closed := r.closed
+	r.m.RUnlock()
+	if closed {
+		return nil, helper.ErrClosed
+	}
+
+	if group == nil {
+		return nil, ErrUnknownGroup
+	}
+
+	if err = wait(ctx, r.member.Latency); err != nil {
+		return
+	}
+
+	spec, ok := r.sim.groups[*group]
+	if !ok {
+		return nil, ErrUnknownGroup
+	}
+
+	if err = r.sim.failure(r.member.Name, spec.Name, op); err != nil {
+		return nil, err
+	}
+
+	return
+}
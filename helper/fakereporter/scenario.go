@@ -0,0 +1,89 @@
+package fakereporter
+
+import "time"
+
+// Scenario declaratively describes a simulated DFSR domain.
+//
+// All offsets within a scenario are relative to the time at which the
+// simulator was created.
+type Scenario struct {
+	Domain   string // DNS name of the domain, such as "example.com"
+	Members  []MemberSpec
+	Groups   []GroupSpec
+	Backlogs []BacklogSpec
+	Failures []FailureSpec
+}
+
+// MemberSpec describes a simulated DFSR member server.
+type MemberSpec struct {
+	Name    string        // Computer name, such as "FS1"
+	Host    string        // Fully qualified domain name. Derived from Name and Domain if empty.
+	Latency time.Duration // Delay added to every call made against the member
+}
+
+// GroupSpec describes a simulated replication group.
+type GroupSpec struct {
+	Name        string
+	Folders     []string         // Replicated folder names
+	Members     []string         // Names of participating members
+	Connections []ConnectionSpec // Connections between participating members
+}
+
+// ConnectionSpec describes a one-way connection between two members of a
+// replication group.
+type ConnectionSpec struct {
+	From     string // Name of the sending member
+	To       string // Name of the receiving member
+	Disabled bool
+}
+
+// BacklogSpec describes how the backlog of a replicated folder evolves over
+// time for a connection.
+//
+// The backlog is interpolated linearly between samples. Before the first
+// sample the backlog is that of the first sample, and after the last sample it
+// is that of the last sample. A spec without samples has a backlog of zero.
+//
+// If Folder is empty the spec applies to all folders in the group.
+type BacklogSpec struct {
+	Group   string
+	Folder  string
+	From    string // Name of the sending member
+	To      string // Name of the receiving member
+	Samples []Sample
+}
+
+// Sample is a backlog count at a point in time.
+type Sample struct {
+	Offset time.Duration
+	Count  int
+}
+
+// FailureSpec describes a failure that will be injected into calls made
+// against a member during a window of time.
+//
+// If Group is non-empty the failure only applies to calls for that group.
+// If Op is non-empty the failure only applies to that operation, which may be
+// one of OpConnect, OpVector, OpBacklog or OpReport. If End is zero the
+// failure lasts forever.
+//
+// If HResult is non-zero the injected error is classified in the same way as
+// errors returned by the DFSR Helper protocol. If both HResult and Err are
+// empty the member is reported as unavailable.
+type FailureSpec struct {
+	Member  string
+	Group   string
+	Op      string
+	Start   time.Duration
+	End     time.Duration
+	HResult uint32 // HRESULT of the error, such as api.E_RPC_SERVER_UNAVAILABLE
+	Err     string // Error message, such as "The RPC server is unavailable."
+}
+
+// Operations that may be targeted by a FailureSpec.
+const (
+	OpConnect = "Connect"
+	OpVector  = "Vector"
+	OpBacklog = "Backlog"
+	OpReport  = "Report"
+)
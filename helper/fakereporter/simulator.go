@@ -0,0 +1,258 @@
+package fakereporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/helper"
+)
+
+// Simulator simulates the DFSR members of a domain according to a scenario.
+// It produces reporters for the simulated members and a matching domain
+// configuration, which allows the helper and monitor packages to be exercised
+// on systems without access to the DFSR Helper protocol.
+//
+// Simulator implements the monitor.Source interface.
+//
+// All of the methods of Simulator are threadsafe.
+type Simulator struct {
+	scenario Scenario
+	start    time.Time
+	clock    func() time.Time
+	domain   core.Domain
+	members  map[string]*MemberSpec // Maps lower-case FQDNs to member specs
+	hosts    map[string]string      // Maps lower-case member names to lower-case FQDNs
+	groups   map[ole.GUID]*GroupSpec
+}
+
+// New returns a simulator for the given scenario that uses the system clock.
+func New(scenario Scenario) (*Simulator, error) {
+	return NewWithClock(scenario, time.Now)
+}
+
+// NewWithClock returns a simulator for the given scenario that uses the
+// provided clock. The offsets in the scenario are relative to the time
+// returned by the clock when NewWithClock is called.
+//
+// An error is returned if the scenario references members or groups that it
+// does not define.
+func NewWithClock(scenario Scenario, clock func() time.Time) (*Simulator, error) {
+	sim := &Simulator{
+		scenario: scenario,
+		start:    clock(),
+		clock:    clock,
+		members:  make(map[string]*MemberSpec),
+		hosts:    make(map[string]string),
+		groups:   make(map[ole.GUID]*GroupSpec),
+	}
+
+	for i := range scenario.Members {
+		m := &sim.scenario.Members[i]
+		if m.Host == "" {
+			m.Host = m.Name + "." + scenario.Domain
+		}
+		name, host := strings.ToLower(m.Name), strings.ToLower(m.Host)
+		if _, exists := sim.hosts[name]; exists {
+			return nil, fmt.Errorf("duplicate member %s in scenario", m.Name)
+		}
+		sim.hosts[name] = host
+		sim.members[host] = m
+	}
+
+	if err := sim.validate(); err != nil {
+		return nil, err
+	}
+
+	sim.domain = sim.build()
+	for i := range sim.domain.Groups {
+		sim.groups[*sim.domain.Groups[i].ID] = &sim.scenario.Groups[i]
+	}
+
+	return sim, nil
+}
+
+// Domain returns the configuration of the simulated domain. Each call returns
+// a new copy that does not share memory with previous calls.
+func (sim *Simulator) Domain() core.Domain {
+	return sim.build()
+}
+
+// Value returns the configuration of the simulated domain along with the time
+// the simulator was created.
+func (sim *Simulator) Value() (*core.Domain, time.Time, error) {
+	return &sim.domain, sim.start, nil
+}
+
+var _ = (helper.ReporterFactory)((*Simulator)(nil).NewReporter) // Compile-time signature check
+
+// NewReporter returns a reporter for the simulated member with the given
+// fully qualified domain name. It can be supplied as the helper.ReporterFactory
+// of an endpoint configuration or a monitor.
+func (sim *Simulator) NewReporter(fqdn string) (helper.Reporter, error) {
+	host := strings.ToLower(fqdn)
+	m, ok := sim.members[host]
+	if !ok {
+		return nil, ErrUnknownMember
+	}
+	if err := wait(nil, m.Latency); err != nil {
+		return nil, err
+	}
+	if err := sim.failure(m.Name, "", OpConnect); err != nil {
+		return nil, err
+	}
+	return &reporter{sim: sim, member: m}, nil
+}
+
+// Elapsed returns the amount of time that has elapsed on the simulator's
+// clock since it was created.
+func (sim *Simulator) Elapsed() time.Duration {
+	return sim.clock().Sub(sim.start)
+}
+
+// backlog returns the simulated backlog of the given folder for a connection
+// at the current time.
+func (sim *Simulator) backlog(group, folder, from, to string) int {
+	elapsed := sim.Elapsed()
+	for i := range sim.scenario.Backlogs {
+		b := &sim.scenario.Backlogs[i]
+		if !strings.EqualFold(b.Group, group) || !strings.EqualFold(b.From, from) || !strings.EqualFold(b.To, to) {
+			continue
+		}
+		if b.Folder != "" && !strings.EqualFold(b.Folder, folder) {
+			continue
+		}
+		return interpolate(b.Samples, elapsed)
+	}
+	return 0
+}
+
+// failure returns the injected error for a call if one is active at the
+// current time.
+func (sim *Simulator) failure(member, group, op string) error {
+	elapsed := sim.Elapsed()
+	for i := range sim.scenario.Failures {
+		f := &sim.scenario.Failures[i]
+		if !strings.EqualFold(f.Member, member) {
+			continue
+		}
+		if f.Group != "" && !strings.EqualFold(f.Group, group) {
+			continue
+		}
+		if f.Op != "" && f.Op != op {
+			continue
+		}
+		if elapsed < f.Start || (f.End != 0 && elapsed >= f.End) {
+			continue
+		}
+		return simulatedError(f)
+	}
+	return nil
+}
+
+func (sim *Simulator) validate() error {
+	groups := make(map[string]*GroupSpec)
+	for i := range sim.scenario.Groups {
+		g := &sim.scenario.Groups[i]
+		name := strings.ToLower(g.Name)
+		if _, exists := groups[name]; exists {
+			return fmt.Errorf("duplicate replication group %s in scenario", g.Name)
+		}
+		groups[name] = g
+		for _, m := range g.Members {
+			if _, ok := sim.hosts[strings.ToLower(m)]; !ok {
+				return fmt.Errorf("replication group %s references unknown member %s", g.Name, m)
+			}
+		}
+		for _, c := range g.Connections {
+			if !containsFold(g.Members, c.From) || !containsFold(g.Members, c.To) {
+				return fmt.Errorf("replication group %s has a connection from %s to %s that references a non-participating member", g.Name, c.From, c.To)
+			}
+		}
+	}
+	for _, b := range sim.scenario.Backlogs {
+		g, ok := groups[strings.ToLower(b.Group)]
+		if !ok {
+			return fmt.Errorf("backlog references unknown replication group %s", b.Group)
+		}
+		if b.Folder != "" && !containsFold(g.Folders, b.Folder) {
+			return fmt.Errorf("backlog references unknown folder %s in replication group %s", b.Folder, b.Group)
+		}
+	}
+	for _, f := range sim.scenario.Failures {
+		if _, ok := sim.hosts[strings.ToLower(f.Member)]; !ok {
+			return fmt.Errorf("failure references unknown member %s", f.Member)
+		}
+	}
+	return nil
+}
+
+// build creates the domain configuration for the scenario.
+func (sim *Simulator) build() (domain core.Domain) {
+	dc := domainDN(sim.scenario.Domain)
+
+	domain.NamingContext = core.NamingContext{
+		ID:          guid("domain", sim.scenario.Domain),
+		DN:          dc,
+		Description: sim.scenario.Domain,
+		Path:        "LDAP://" + dc,
+	}
+
+	computers := make(map[string]core.Computer, len(sim.scenario.Members))
+	for _, m := range sim.scenario.Members {
+		computers[strings.ToLower(m.Name)] = core.Computer{
+			DN:   "CN=" + m.Name + ",CN=Computers," + dc,
+			Host: m.Host,
+		}
+	}
+
+	for _, g := range sim.scenario.Groups {
+		gdn := "CN=" + g.Name + ",CN=DFSR-GlobalSettings,CN=System," + dc
+		group := core.Group{
+			Name: g.Name,
+			ID:   guid("group", g.Name),
+		}
+
+		for _, f := range g.Folders {
+			group.Folders = append(group.Folders, core.Folder{
+				Name: f,
+				ID:   guid("folder", g.Name+"\\"+f),
+			})
+		}
+
+		dns := make(map[string]string, len(g.Members))
+		for _, m := range g.Members {
+			dns[strings.ToLower(m)] = "CN=" + m + ",CN=Topology," + gdn
+		}
+
+		for _, m := range g.Members {
+			member := core.Member{
+				MemberInfo: core.MemberInfo{
+					Name:     m,
+					ID:       guid("member", g.Name+"\\"+m),
+					Computer: computers[strings.ToLower(m)],
+					DN:       dns[strings.ToLower(m)],
+				},
+			}
+			for _, c := range g.Connections {
+				if !strings.EqualFold(c.To, m) {
+					continue
+				}
+				member.Connections = append(member.Connections, core.Connection{
+					Name:     c.From,
+					ID:       guid("connection", g.Name+"\\"+c.From+"\\"+c.To),
+					MemberDN: dns[strings.ToLower(c.From)],
+					Enabled:  !c.Disabled,
+					Computer: computers[strings.ToLower(c.From)],
+				})
+			}
+			group.Members = append(group.Members, member)
+		}
+
+		domain.Groups = append(domain.Groups, group)
+	}
+
+	return
+}
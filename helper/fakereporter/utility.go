@@ -0,0 +1,100 @@
+package fakereporter
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/helper/api"
+)
+
+// guid returns a deterministic GUID for the given kind of object and name.
+func guid(kind, name string) *ole.GUID {
+	sum := sha1.Sum([]byte(kind + ":" + strings.ToLower(name)))
+	g := &ole.GUID{
+		Data1: binary.BigEndian.Uint32(sum[0:4]),
+		Data2: binary.BigEndian.Uint16(sum[4:6]),
+		Data3: binary.BigEndian.Uint16(sum[6:8]),
+	}
+	copy(g.Data4[:], sum[8:16])
+	return g
+}
+
+func domainDN(domain string) string {
+	components := strings.Split(domain, ".")
+	for i := range components {
+		components[i] = "DC=" + components[i]
+	}
+	return strings.Join(components, ",")
+}
+
+// interpolate returns the backlog at the given offset.
+func interpolate(samples []Sample, offset time.Duration) int {
+	if len(samples) == 0 {
+		return 0
+	}
+	if offset <= samples[0].Offset {
+		return samples[0].Count
+	}
+	for i := 1; i < len(samples); i++ {
+		a, b := samples[i-1], samples[i]
+		if offset >= b.Offset {
+			continue
+		}
+		span := b.Offset - a.Offset
+		if span <= 0 {
+			return b.Count
+		}
+		progress := float64(offset-a.Offset) / float64(span)
+		return a.Count + int(progress*float64(b.Count-a.Count))
+	}
+	return samples[len(samples)-1].Count
+}
+
+// wait blocks for the given duration or until ctx is cancelled. A nil ctx is
+// never cancelled.
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	if ctx == nil {
+		<-t.C
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func simulatedError(f *FailureSpec) error {
+	hr := uintptr(f.HResult)
+	if hr == 0 {
+		if f.Err != "" {
+			return errors.New(f.Err)
+		}
+		hr = api.E_RPC_SERVER_UNAVAILABLE
+	}
+	err := api.NewError(hr)
+	if f.Err != "" {
+		err.Err = errors.New(f.Err)
+	}
+	return err
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
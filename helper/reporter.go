@@ -23,6 +23,12 @@ type Reporter interface {
 	Report(ctx context.Context, group *ole.GUID, vector *versionvector.Vector, backlog, files bool) (data *ole.SafeArrayConversion, report string, call callstat.Call, err error)
 }
 
+// ReporterFactory creates a Reporter for the DFSR member with the given fully
+// qualified domain name. NewReporter is a ReporterFactory.
+type ReporterFactory func(fqdn string) (Reporter, error)
+
+var _ = (ReporterFactory)(NewReporter) // Compile-time signature check
+
 var _ = (Reporter)((*reporter)(nil)) // Compile-time interface compliance check
 
 // reporter provides access to the system API for DFSR health reports.
@@ -120,8 +126,14 @@ func (r *reporter) Backlog(ctx context.Context, vector *versionvector.Vector) (b
 	default:
 	}
 
+	vdata, release, err := vectorData(vector)
+	if err != nil {
+		return
+	}
+	defer release()
+
 	// TODO: Check dimensions of the returned backlog for sanity
-	sa, err := r.iface.GetReferenceBacklogCounts(vector.Data())
+	sa, err := r.iface.GetReferenceBacklogCounts(vdata)
 	if err != nil {
 		return
 	}
@@ -168,7 +180,12 @@ func (r *reporter) Report(ctx context.Context, group *ole.GUID, vector *versionv
 
 	var vdata *ole.SafeArrayConversion
 	if backlog {
-		vdata = vector.Data()
+		var release func()
+		vdata, release, err = vectorData(vector)
+		if err != nil {
+			return
+		}
+		defer release()
 	}
 
 	// TODO: Check dimensions of the returned backlog for sanity
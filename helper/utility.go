@@ -3,9 +3,10 @@ package helper
 import (
 	"errors"
 	"log"
-	"strings"
 
 	"github.com/go-ole/go-ole"
+	"gopkg.in/dfsr.v0/helper/api"
+	"gopkg.in/dfsr.v0/versionvector"
 )
 
 func makeBacklog(sa *ole.SafeArrayConversion) (backlog []int) {
@@ -24,11 +25,40 @@ func makeBacklog(sa *ole.SafeArrayConversion) (backlog []int) {
 	return
 }
 
+// vectorData returns the safe array data of the given vector. Vectors that are
+// backed by native values are encoded, in which case the returned release
+// function frees the encoded data.
+func vectorData(vector *versionvector.Vector) (sa *ole.SafeArrayConversion, release func(), err error) {
+	if sa = vector.Data(); sa != nil {
+		return sa, func() {}, nil
+	}
+
+	values, err := vector.Values()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoded, err := versionvector.Encode(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return encoded.Data(), encoded.Close, nil
+}
+
 // IsUnavailableErr returns true if the given error indicates that a server is
 // disconnected or offline.
 func IsUnavailableErr(err error) bool {
-	if err == nil {
+	return errors.Is(err, api.ErrUnavailable)
+}
+
+// IsCancellationErr returns true if the given error indicates that a call was
+// cancelled or timed out, either locally or by the remote server.
+func IsCancellationErr(err error) bool {
+	switch api.Classify(err) {
+	case api.CategoryCanceled, api.CategoryTimeout:
+		return true
+	default:
 		return false
 	}
-	return strings.Contains(err.Error(), "The RPC server is unavailable")
 }
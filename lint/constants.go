@@ -0,0 +1,12 @@
+package lint
+
+import "errors"
+
+var (
+	// ErrInvalidSeverity is returned when a severity is not recognized.
+	ErrInvalidSeverity = errors.New("The finding severity is invalid.")
+
+	// ErrInvalidSuppression is returned when a suppression does not have a
+	// known finding code.
+	ErrInvalidSuppression = errors.New("The lint suppression is invalid.")
+)
@@ -0,0 +1,26 @@
+// Package lint checks DFSR configuration for integrity problems.
+//
+// Each finding has a severity and a stable code, so that known exceptions can
+// be suppressed and automation can fail when new findings appear. The codes
+// and their severities are:
+//
+//   DFSR001 error    Member computer without a host name, never queried
+//   DFSR002 error    Connection from a computer that is not a group member
+//   DFSR003 warning  More than one connection between the same members
+//   DFSR004 warning  Group without replicated folders
+//   DFSR005 warning  Group with a single member, which replicates nothing
+//   DFSR006 error    Group whose configuration could not be retrieved
+//   DFSR007 warning  Group without members
+//
+// Findings are checked and filtered like this:
+//
+//   findings := lint.Domain(&domain)
+//   findings, _ = lint.Filter(findings, suppressions)
+//   for _, finding := range findings {
+//     fmt.Println(finding)
+//   }
+//
+// Suppressions are typically loaded from a YAML file with LoadSuppressions.
+// The file holds a list of suppressions, each of which names a code and
+// optionally a group, member and connection, along with a reason.
+package lint
@@ -0,0 +1,104 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+)
+
+// Domain checks the configuration of every replication group in the domain.
+func Domain(domain *core.Domain) (findings []Finding) {
+	for g := range domain.Groups {
+		findings = append(findings, Group(&domain.Groups[g])...)
+	}
+	return
+}
+
+// Group checks the configuration of the given replication group. A group
+// whose configuration could not be retrieved is reported, but not checked.
+//
+// The source of a connection is matched against the distinguished names of
+// the group's members. Connections of the SYSVOL group refer to the NTDS
+// settings of the source domain controller instead, so a connection whose
+// computer is the computer of a member is also considered to be from that
+// member.
+func Group(group *core.Group) (findings []Finding) {
+	add := func(code Code, member, connection, format string, args ...interface{}) {
+		findings = append(findings, Finding{
+			Code:       code,
+			Severity:   codes[code],
+			Group:      group.Name,
+			Member:     member,
+			Connection: connection,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if group.Err != nil {
+		add(GroupFailed, "", "", "The configuration of the group could not be retrieved: %v", group.Err)
+		return
+	}
+
+	if len(group.Folders) == 0 {
+		add(NoFolders, "", "", "The group has no replicated folders")
+	}
+	switch len(group.Members) {
+	case 0:
+		add(NoMembers, "", "", "The group has no members, so nothing is replicated")
+	case 1:
+		add(SingleMember, "", "", "The group has a single member, so nothing is replicated")
+	}
+
+	for m := range group.Members {
+		member := &group.Members[m]
+
+		if member.Computer.Host == "" {
+			if member.Computer.DN == "" {
+				add(NoHost, member.Name, "", "The member does not refer to a computer, so its backlogs are not queried")
+			} else {
+				add(NoHost, member.Name, "", "The member's computer %s has no host name, so its backlogs are not queried", member.Computer.DN)
+			}
+		}
+
+		sources := make(map[string]string) // Maps connection sources to the first connection from them
+		for c := range member.Connections {
+			conn := &member.Connections[c]
+
+			var key string
+			if source := sourceMember(group, conn); source != nil {
+				key = strings.ToLower(source.DN)
+			} else {
+				key = strings.ToLower(conn.MemberDN)
+				add(UnknownSource, member.Name, conn.Name, "The source of the connection, %s, is not a member of the group", conn.MemberDN)
+			}
+
+			if first, ok := sources[key]; ok {
+				add(DuplicateConnection, member.Name, conn.Name, "The connection has the same source as connection %s", first)
+				continue
+			}
+			sources[key] = conn.Name
+		}
+	}
+
+	return
+}
+
+// sourceMember returns the member of the group that is the source of the
+// given connection, or nil if there is no such member.
+func sourceMember(group *core.Group, conn *core.Connection) *core.Member {
+	for m := range group.Members {
+		if conn.MemberDN != "" && strings.EqualFold(group.Members[m].DN, conn.MemberDN) {
+			return &group.Members[m]
+		}
+	}
+	if !group.Sysvol() || conn.Computer.DN == "" {
+		return nil
+	}
+	for m := range group.Members {
+		if strings.EqualFold(group.Members[m].Computer.DN, conn.Computer.DN) {
+			return &group.Members[m]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Suppression suppresses findings that are known exceptions. Empty values
+// match everything, except for the code, which is required. Names are
+// matched without regard to case.
+type Suppression struct {
+	Code       Code   `yaml:"code"`
+	Group      string `yaml:"group,omitempty"`
+	Member     string `yaml:"member,omitempty"`
+	Connection string `yaml:"connection,omitempty"`
+	Reason     string `yaml:"reason,omitempty"` // Why the finding is expected, for the benefit of readers
+}
+
+// Validate returns an error if the suppression does not have a known code.
+func (s *Suppression) Validate() error {
+	if _, ok := codes[Code(strings.ToUpper(string(s.Code)))]; !ok {
+		return fmt.Errorf("%w: unknown code \"%s\"", ErrInvalidSuppression, s.Code)
+	}
+	return nil
+}
+
+// Matches reports whether the suppression applies to the given finding.
+func (s *Suppression) Matches(f *Finding) bool {
+	return strings.EqualFold(string(s.Code), string(f.Code)) &&
+		matches(s.Group, f.Group) && matches(s.Member, f.Member) && matches(s.Connection, f.Connection)
+}
+
+// Filter returns the findings that are not matched by any of the given
+// suppressions, and the number of findings that were suppressed.
+func Filter(findings []Finding, suppressions []Suppression) (remaining []Finding, suppressed int) {
+	for f := range findings {
+		if suppressedBy(&findings[f], suppressions) {
+			suppressed++
+			continue
+		}
+		remaining = append(remaining, findings[f])
+	}
+	return
+}
+
+// LoadSuppressions loads a list of suppressions from the YAML file at path.
+func LoadSuppressions(path string) (suppressions []Suppression, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(data, &suppressions); err != nil {
+		return nil, err
+	}
+	for i := range suppressions {
+		if err = suppressions[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func suppressedBy(f *Finding, suppressions []Suppression) bool {
+	for s := range suppressions {
+		if suppressions[s].Matches(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern, value string) bool {
+	return pattern == "" || strings.EqualFold(pattern, value)
+}
@@ -0,0 +1,104 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a finding. Severities are ordered, so that
+// findings can be compared against a minimum severity.
+type Severity int
+
+// Finding severities.
+const (
+	// Info findings describe unusual configuration that is not a problem.
+	Info Severity = iota + 1
+	// Warning findings describe configuration that is probably a mistake.
+	Warning
+	// Error findings describe configuration that prevents replication or
+	// monitoring.
+	Error
+)
+
+// String returns a string representation of the severity.
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// MarshalText returns the string representation of the severity.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a severity from its string representation.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for _, candidate := range []Severity{Info, Warning, Error} {
+		if strings.EqualFold(string(text), candidate.String()) {
+			*s = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: \"%s\"", ErrInvalidSeverity, text)
+}
+
+// Code identifies the kind of a finding. Codes are stable across releases.
+type Code string
+
+// Finding codes.
+const (
+	NoHost              Code = "DFSR001" // Member computer without a host name
+	UnknownSource       Code = "DFSR002" // Connection from a computer that is not a member of the group
+	DuplicateConnection Code = "DFSR003" // More than one connection between the same members
+	NoFolders           Code = "DFSR004" // Group without replicated folders
+	SingleMember        Code = "DFSR005" // Group with a single member
+	GroupFailed         Code = "DFSR006" // Group whose configuration could not be retrieved
+	NoMembers           Code = "DFSR007" // Group without members
+)
+
+// codes holds the default severity of each finding code.
+var codes = map[Code]Severity{
+	NoHost:              Error,
+	UnknownSource:       Error,
+	DuplicateConnection: Warning,
+	NoFolders:           Warning,
+	SingleMember:        Warning,
+	GroupFailed:         Error,
+	NoMembers:           Warning,
+}
+
+// Finding is a problem found in the configuration of a replication group.
+type Finding struct {
+	Code       Code     `json:"code" yaml:"code"`
+	Severity   Severity `json:"severity" yaml:"severity"`
+	Group      string   `json:"group" yaml:"group"`
+	Member     string   `json:"member,omitempty" yaml:"member,omitempty"`         // Affected member, if any
+	Connection string   `json:"connection,omitempty" yaml:"connection,omitempty"` // Affected connection of the member, if any
+	Message    string   `json:"message" yaml:"message"`
+}
+
+// Path returns the group, member and connection affected by the finding,
+// separated by slashes.
+func (f *Finding) Path() string {
+	path := f.Group
+	if f.Member != "" {
+		path += "/" + f.Member
+	}
+	if f.Connection != "" {
+		path += "/" + f.Connection
+	}
+	return path
+}
+
+// String returns a string representation of the finding.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s %s: %s", f.Code, f.Severity, f.Path(), f.Message)
+}
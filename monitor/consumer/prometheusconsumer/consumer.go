@@ -0,0 +1,224 @@
+package prometheusconsumer
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"gopkg.in/dfsr.v0/callstat"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/monitor"
+)
+
+const namespace = "dfsr"
+
+// Consumer represents a Prometheus consumer of DFSR monitor backlog updates.
+//
+// Consumer implements http.Handler and serves the collected metrics in the
+// Prometheus exposition format. It is typically registered at /metrics.
+type Consumer struct {
+	ch       <-chan *monitor.Update
+	registry *prometheus.Registry
+	handler  http.Handler
+	backlog  *prometheus.GaugeVec
+	errors   *prometheus.CounterVec
+	calls    *prometheus.HistogramVec
+	sites    *prometheus.GaugeVec
+
+	mutex  sync.Mutex
+	series map[connection][]string // Maps known connections to the folder names exported for them
+	pairs  map[core.SitePair]bool  // Site pairs exported by the last complete update
+}
+
+// connection identifies a one-way connection within a replication group.
+type connection struct {
+	Group string
+	From  string
+	To    string
+}
+
+// New returns a new Prometheus consumer of DFSR monitor backlog updates. The
+// returned consumer will function until the provided update channel is closed.
+func New(updates <-chan *monitor.Update) *Consumer {
+	c := &Consumer{
+		ch:       updates,
+		registry: prometheus.NewRegistry(),
+		backlog: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "backlog_files",
+			Help:      "Number of files in the backlog of a replicated folder from one member to another.",
+		}, []string{"group", "source", "destination", "folder"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backlog_query_errors_total",
+			Help:      "Number of backlog queries that failed from one member to another.",
+		}, []string{"group", "source", "destination"}),
+		calls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "call_duration_seconds",
+			Help:      "Wall time of the calls made while querying backlogs.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"call"}),
+		sites: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "site_backlog_files",
+			Help:      "Combined backlog of all connections from one site to another.",
+		}, []string{"source_site", "destination_site"}),
+		series: make(map[connection][]string),
+		pairs:  make(map[core.SitePair]bool),
+	}
+	c.registry.MustRegister(c.backlog, c.errors, c.calls, c.sites)
+	c.handler = promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+	go c.run()
+	return c
+}
+
+// ServeHTTP serves the current set of metrics to a Prometheus scraper.
+func (c *Consumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.handler.ServeHTTP(w, r)
+}
+
+func (c *Consumer) run() {
+	for {
+		update, ok := <-c.ch
+		if !ok {
+			return
+		}
+
+		var (
+			backlogs []*core.Backlog
+			seen     = make(map[connection]bool, update.Size())
+		)
+		for backlog := range update.Listen() {
+			backlogs = append(backlogs, backlog)
+			seen[key(backlog)] = true
+			c.record(backlog)
+		}
+
+		// Only prune when the update is complete, otherwise connections that
+		// weren't queried before cancellation would lose their series.
+		if len(backlogs) == update.Size() {
+			c.prune(seen)
+			c.recordSites(backlogs)
+		}
+	}
+}
+
+func (c *Consumer) record(backlog *core.Backlog) {
+	k := key(backlog)
+
+	c.observe(&backlog.Call)
+
+	if backlog.Err != nil || len(backlog.Folders) == 0 {
+		c.errors.WithLabelValues(k.Group, k.From, k.To).Inc()
+		c.drop(k)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	folders := make([]string, 0, len(backlog.Folders))
+	for f := range backlog.Folders {
+		value := backlog.Folders[f].Backlog
+		if value < 0 {
+			// Indicates per-folder query error
+			c.errors.WithLabelValues(k.Group, k.From, k.To).Inc()
+			continue
+		}
+		name := backlog.Folders[f].Folder.Name
+		c.backlog.WithLabelValues(k.Group, k.From, k.To, name).Set(float64(value))
+		folders = append(folders, name)
+	}
+
+	// Remove folders that were present in the last update but not this one
+	for _, name := range c.series[k] {
+		if !contains(folders, name) {
+			c.backlog.DeleteLabelValues(k.Group, k.From, k.To, name)
+		}
+	}
+
+	c.series[k] = folders
+}
+
+// recordSites exports the combined backlog of each pair of known sites. It
+// must be called with the complete set of backlogs from an update. Pairs with
+// a failed connection are not exported, since their total would be too low.
+func (c *Consumer) recordSites(backlogs []*core.Backlog) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	pairs := make(map[core.SitePair]bool)
+	for _, sb := range core.SiteBacklogs(backlogs) {
+		if sb.From == "" || sb.To == "" || sb.Failed > 0 {
+			continue
+		}
+		c.sites.WithLabelValues(sb.From, sb.To).Set(float64(sb.Backlog))
+		pairs[sb.SitePair] = true
+	}
+
+	for pair := range c.pairs {
+		if !pairs[pair] {
+			c.sites.DeleteLabelValues(pair.From, pair.To)
+		}
+	}
+	c.pairs = pairs
+}
+
+func (c *Consumer) observe(call *callstat.Call) {
+	if call.Description != "" && !call.Start.IsZero() {
+		c.calls.WithLabelValues(call.Description).Observe(call.Duration().Seconds())
+	}
+	for i := range call.Inner {
+		c.observe(&call.Inner[i])
+	}
+}
+
+// drop removes the backlog series of the given connection so that stale
+// values are not exported.
+func (c *Consumer) drop(k connection) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, name := range c.series[k] {
+		c.backlog.DeleteLabelValues(k.Group, k.From, k.To, name)
+	}
+	c.series[k] = nil // Retain the connection so that its errors can be pruned
+}
+
+// prune removes all series belonging to connections that are no longer
+// present in the topology.
+func (c *Consumer) prune(seen map[connection]bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for k, folders := range c.series {
+		if seen[k] {
+			continue
+		}
+		for _, name := range folders {
+			c.backlog.DeleteLabelValues(k.Group, k.From, k.To, name)
+		}
+		c.errors.DeleteLabelValues(k.Group, k.From, k.To)
+		delete(c.series, k)
+	}
+}
+
+func key(backlog *core.Backlog) connection {
+	return connection{
+		Group: backlog.Group.Name,
+		From:  backlog.From,
+		To:    backlog.To,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package prometheusconsumer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/monitor"
+)
+
+func newConsumer() *Consumer {
+	updates := make(chan *monitor.Update)
+	close(updates)
+	return New(updates)
+}
+
+func TestRecord(t *testing.T) {
+	group := &core.Group{Name: "Data"}
+	share := &core.Folder{Name: "Share"}
+	archive := &core.Folder{Name: "Archive"}
+
+	tests := []struct {
+		name    string
+		backlog core.Backlog
+		values  map[string]float64 // Exported backlog of each folder
+		errors  float64
+	}{
+		{"backlogs", core.Backlog{
+			Folders: []core.FolderBacklog{{Folder: share, Backlog: 12}, {Folder: archive, Backlog: 0}},
+		}, map[string]float64{"Share": 12, "Archive": 0}, 0},
+		{"negative folder backlog", core.Backlog{
+			Folders: []core.FolderBacklog{{Folder: share, Backlog: 3}, {Folder: archive, Backlog: -1}},
+		}, map[string]float64{"Share": 3}, 1},
+		{"failed query", core.Backlog{
+			Err: errors.New("query failed"),
+		}, nil, 1},
+		{"no folders", core.Backlog{}, nil, 1},
+	}
+
+	for _, tt := range tests {
+		c := newConsumer()
+		backlog := tt.backlog
+		backlog.Group, backlog.From, backlog.To = group, "fs1", "fs2"
+		c.record(&backlog)
+
+		if got := testutil.CollectAndCount(c.backlog); got != len(tt.values) {
+			t.Errorf("%s: %d backlog series, want %d", tt.name, got, len(tt.values))
+		}
+		for folder, want := range tt.values {
+			if got := testutil.ToFloat64(c.backlog.WithLabelValues("Data", "fs1", "fs2", folder)); got != want {
+				t.Errorf("%s: backlog of %s = %v, want %v", tt.name, folder, got, want)
+			}
+		}
+		if got := testutil.ToFloat64(c.errors.WithLabelValues("Data", "fs1", "fs2")); got != tt.errors {
+			t.Errorf("%s: errors = %v, want %v", tt.name, got, tt.errors)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Package prometheusconsumer exposes DFSR monitor backlog updates as
+// Prometheus metrics.
+//
+// Backlog counts are exported as gauges labeled by replication group, source
+// member, destination member and replicated folder. Failed backlog queries
+// are counted per connection and the durations of all calls made while
+// querying backlogs are recorded in a histogram. Series belonging to
+// connections that disappear from the topology are removed.
+//
+// When the sites of the members are known, the combined backlog of all
+// connections from one site to another is exported as well, once every
+// connection of an update has been queried.
+package prometheusconsumer
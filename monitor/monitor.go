@@ -19,10 +19,18 @@ type Monitor struct {
 	interval time.Duration
 	cache    time.Duration
 	limit    uint
+	factory  helper.ReporterFactory
 	instance *poller.Poller
 	closed   bool
 }
 
+// Config holds optional settings for a monitor.
+type Config struct {
+	// Factory is used to create the reporters that query DFSR members. If
+	// Factory is nil the DFSR Helper protocol will be used.
+	Factory helper.ReporterFactory
+}
+
 // New creates a new Monitor with the given source and polling interval.
 //
 // If cache is nonzero then the monitor will cache version vectors for
@@ -33,11 +41,21 @@ type Monitor struct {
 //
 // The returned monitor will not function until start is called.
 func New(source Source, interval time.Duration, cache time.Duration, limit uint) *Monitor {
+	return NewWithConfig(source, interval, cache, limit, Config{})
+}
+
+// NewWithConfig creates a new Monitor with the given source, polling interval
+// and configuration. The cache and limit arguments have the same meaning as
+// they do for New.
+//
+// The returned monitor will not function until start is called.
+func NewWithConfig(source Source, interval time.Duration, cache time.Duration, limit uint, config Config) *Monitor {
 	return &Monitor{
 		source:   source,
 		interval: interval,
 		cache:    cache,
 		limit:    limit,
+		factory:  config.Factory,
 	}
 }
 
@@ -91,6 +109,8 @@ func (m *Monitor) Start() error {
 		config.Limiting = false
 	}
 
+	config.Factory = m.factory
+
 	client := helper.NewClientWithConfig(config)
 
 	m.instance = poller.New(&worker{
@@ -0,0 +1,114 @@
+package monitor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/helper/api"
+	"gopkg.in/dfsr.v0/helper/fakereporter"
+	"gopkg.in/dfsr.v0/monitor"
+)
+
+var scenario = fakereporter.Scenario{
+	Domain: "example.com",
+	Members: []fakereporter.MemberSpec{
+		{Name: "FS1"},
+		{Name: "FS2"},
+		{Name: "FS3"},
+	},
+	Groups: []fakereporter.GroupSpec{
+		{
+			Name:    "Data",
+			Folders: []string{"Share", "Archive"},
+			Members: []string{"FS1", "FS2", "FS3"},
+			Connections: []fakereporter.ConnectionSpec{
+				{From: "FS1", To: "FS2"},
+				{From: "FS2", To: "FS1"},
+				{From: "FS1", To: "FS3"},
+			},
+		},
+	},
+	Backlogs: []fakereporter.BacklogSpec{
+		{Group: "Data", Folder: "Share", From: "FS1", To: "FS2", Samples: []fakereporter.Sample{{Count: 12}}},
+		{Group: "Data", Folder: "Archive", From: "FS1", To: "FS2", Samples: []fakereporter.Sample{{Count: 3}}},
+		{Group: "Data", From: "FS2", To: "FS1", Samples: []fakereporter.Sample{{Count: 5}}},
+	},
+	Failures: []fakereporter.FailureSpec{
+		{Member: "FS3"},
+	},
+}
+
+func poll(t *testing.T, sim *fakereporter.Simulator) map[string]*core.Backlog {
+	t.Helper()
+
+	mon := monitor.NewWithConfig(sim, time.Hour, 0, 1, monitor.Config{Factory: sim.NewReporter})
+	defer mon.Close()
+
+	updates := mon.Listen(1)
+	if err := mon.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	mon.Update()
+
+	var update *monitor.Update
+	select {
+	case update = <-updates:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for an update")
+	}
+
+	backlogs := make(map[string]*core.Backlog)
+	for backlog := range update.Listen() {
+		backlogs[backlog.From+" "+backlog.To] = backlog
+	}
+	return backlogs
+}
+
+func TestMonitorSimulator(t *testing.T) {
+	sim, err := fakereporter.New(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backlogs := poll(t, sim)
+	if len(backlogs) != 3 {
+		t.Fatalf("received %d backlogs, want 3", len(backlogs))
+	}
+
+	tests := []struct {
+		connection string
+		folders    map[string]int
+	}{
+		{"FS1.example.com FS2.example.com", map[string]int{"Share": 12, "Archive": 3}},
+		{"FS2.example.com FS1.example.com", map[string]int{"Share": 5, "Archive": 5}},
+	}
+	for _, tt := range tests {
+		backlog, ok := backlogs[tt.connection]
+		if !ok {
+			t.Errorf("%s: no backlog received", tt.connection)
+			continue
+		}
+		if backlog.Err != nil {
+			t.Errorf("%s: %v", tt.connection, backlog.Err)
+			continue
+		}
+		if len(backlog.Folders) != len(tt.folders) {
+			t.Errorf("%s: %d folders, want %d", tt.connection, len(backlog.Folders), len(tt.folders))
+		}
+		for _, fb := range backlog.Folders {
+			if want := tt.folders[fb.Folder.Name]; fb.Backlog != want {
+				t.Errorf("%s: backlog of %s = %d, want %d", tt.connection, fb.Folder.Name, fb.Backlog, want)
+			}
+		}
+	}
+
+	failed, ok := backlogs["FS1.example.com FS3.example.com"]
+	if !ok {
+		t.Fatal("no backlog received for the failed member")
+	}
+	if !errors.Is(failed.Err, api.ErrUnavailable) {
+		t.Errorf("backlog error = %v, want %v", failed.Err, api.ErrUnavailable)
+	}
+}
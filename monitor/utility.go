@@ -2,13 +2,22 @@ package monitor
 
 import (
 	"context"
+	"time"
 
 	"gopkg.in/dfsr.v0/core"
 )
 
+// connections returns a backlog for each enabled connection in the domain.
+// Backlogs of connections whose schedule does not currently allow replication
+// are marked as unscheduled. Backlogs of connections with schedules in the
+// local time of the receiving member are never marked as unscheduled.
 func connections(domain *core.Domain) (output []*core.Backlog) {
+	now := time.Now()
 	for gi := 0; gi < len(domain.Groups); gi++ {
 		group := &domain.Groups[gi]
+		if group.Err != nil {
+			continue // Configuration for this group is unavailable
+		}
 
 		for mi := 0; mi < len(group.Members); mi++ {
 			member := &group.Members[mi]
@@ -27,10 +36,18 @@ func connections(domain *core.Domain) (output []*core.Backlog) {
 					continue
 				}
 
+				// The time zone of the receiving member is not recorded in the
+				// directory, so schedules in its local time are not evaluated
+				schedule := group.ConnectionSchedule(conn)
+				unscheduled := schedule != nil && !schedule.Local && !schedule.Allowed(now)
+
 				output = append(output, &core.Backlog{
-					Group: group,
-					From:  from,
-					To:    to,
+					Group:        group,
+					From:         from,
+					To:           to,
+					FromComputer: conn.Computer,
+					ToComputer:   member.Computer,
+					Unscheduled:  unscheduled,
 				})
 			}
 		}
@@ -0,0 +1,24 @@
+// Package report parses the XML health reports produced by DFSR members.
+//
+// Reports are retrieved with the GetReport method of the DFSR Helper
+// protocol, which is exposed by helper.Client.Report. The XML describes the
+// reporting member, the state of the replicated folders it hosts, the
+// backlogged files for each folder when requested, and the error and warning
+// events that the member has recorded:
+//
+//   _, xml, _, err := client.Report(ctx, server, group.ID, vector, true, true)
+//   if err != nil {
+//     return err
+//   }
+//   r, err := report.Parse(xml)
+//   if err != nil {
+//     return err
+//   }
+//   for _, event := range r.Errors() {
+//     fmt.Println(event.Message)
+//   }
+//
+// Element and attribute names are matched case-insensitively and unknown
+// elements are ignored, so reports produced by different versions of Windows
+// can be parsed by the same code.
+package report
@@ -0,0 +1,23 @@
+package report
+
+import "errors"
+
+var (
+	// ErrEmptyReport is returned when a report does not contain any XML
+	// elements.
+	ErrEmptyReport = errors.New("The report does not contain any data.")
+)
+
+// ParseError is returned when report XML is malformed.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return "Unable to parse DFSR report: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying XML error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,326 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses the given DFSR health report XML.
+func Parse(data string) (*Report, error) {
+	return ParseReader(strings.NewReader(data))
+}
+
+// ParseReader parses DFSR health report XML from the given reader.
+func ParseReader(r io.Reader) (*Report, error) {
+	root, err := decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Generated: root.time("generated", "timestamp", "time"),
+	}
+
+	if n := root.child("serverinfo", "member", "server"); n != nil {
+		report.Member = member(n)
+	}
+
+	for _, n := range root.descendants("replicationgroup", "group") {
+		report.Groups = append(report.Groups, group(n))
+	}
+
+	for _, n := range root.descendants("event", "error", "warning", "information") {
+		report.Events = append(report.Events, event(n))
+	}
+
+	return report, nil
+}
+
+// Errors returns the error events contained in the report.
+func (r *Report) Errors() []Event {
+	return r.events(SeverityError)
+}
+
+// Warnings returns the warning events contained in the report.
+func (r *Report) Warnings() []Event {
+	return r.events(SeverityWarning)
+}
+
+// Folder returns the replicated folder with the given GUID, or nil if the
+// report does not contain it.
+func (r *Report) Folder(id string) *Folder {
+	for g := range r.Groups {
+		for f := range r.Groups[g].Folders {
+			if strings.EqualFold(trimGUID(r.Groups[g].Folders[f].ID), trimGUID(id)) {
+				return &r.Groups[g].Folders[f]
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Report) events(severity Severity) (events []Event) {
+	for _, e := range r.Events {
+		if e.Severity == severity {
+			events = append(events, e)
+		}
+	}
+	return
+}
+
+// Sum returns the total backlog of the folder across all reference members.
+func (f *Folder) Sum() (sum int) {
+	for _, b := range f.Backlogs {
+		if b.Count > 0 {
+			sum += b.Count
+		}
+	}
+	return
+}
+
+func member(n *node) Member {
+	return Member{
+		Name:         n.attr("name", "computername"),
+		ID:           n.attr("guid", "id", "memberguid"),
+		Domain:       n.attr("domain", "domainname"),
+		Host:         n.attr("dnsname", "host", "dnshostname"),
+		Site:         n.attr("site", "sitename"),
+		OSVersion:    n.attr("osversion", "os"),
+		DFSRVersion:  n.attr("dfsrversion", "version"),
+		ServiceState: n.attr("servicestate", "state"),
+		Uptime:       time.Duration(n.int("uptime")) * time.Second,
+	}
+}
+
+func group(n *node) Group {
+	g := Group{
+		Name: n.attr("name", "groupname"),
+		ID:   n.attr("guid", "id", "groupguid"),
+	}
+	for _, f := range n.descendants("replicatedfolder", "contentset", "folder") {
+		g.Folders = append(g.Folders, folder(f))
+	}
+	return g
+}
+
+func folder(n *node) Folder {
+	f := Folder{
+		Name:          n.attr("name", "foldername"),
+		ID:            n.attr("guid", "id", "folderguid"),
+		State:         folderState(n.attr("state", "replicationstate")),
+		RootPath:      n.attr("rootpath", "path"),
+		StagingPath:   n.attr("stagingpath"),
+		StagingQuota:  n.int("stagingquota", "stagingsizeinmb"),
+		StagingUsage:  n.int("stagingusage", "stagingusedinmb"),
+		ConflictQuota: n.int("conflictquota", "conflictsizeinmb"),
+		ConflictUsage: n.int("conflictusage", "conflictusedinmb"),
+	}
+	for _, b := range n.descendants("backlog") {
+		f.Backlogs = append(f.Backlogs, backlog(b))
+	}
+	return f
+}
+
+func backlog(n *node) Backlog {
+	b := Backlog{
+		Member:   n.attr("membername", "member", "name"),
+		MemberID: n.attr("memberguid", "memberid", "guid"),
+		Count:    int(n.int("count", "backlogcount")),
+	}
+	for _, f := range n.descendants("file") {
+		b.Files = append(b.Files, File{
+			Name:     f.attr("name", "filename"),
+			Path:     f.attr("path", "fullpath"),
+			UID:      f.attr("uid"),
+			GVSN:     f.attr("gvsn"),
+			Size:     f.int("size"),
+			Modified: f.time("modified", "updatetime", "time"),
+		})
+	}
+	if b.Count == 0 {
+		b.Count = len(b.Files)
+	}
+	return b
+}
+
+func event(n *node) Event {
+	severity := n.attr("severity", "type", "level")
+	if severity == "" {
+		severity = n.name
+	}
+	message := n.attr("message", "description")
+	if message == "" {
+		message = strings.TrimSpace(n.text)
+	}
+	return Event{
+		ID:       int(n.int("id", "eventid")),
+		Severity: eventSeverity(severity),
+		Time:     n.time("time", "timestamp", "timegenerated"),
+		Source:   n.attr("source"),
+		Group:    n.attr("groupname", "group"),
+		Folder:   n.attr("foldername", "folder"),
+		Message:  message,
+	}
+}
+
+func folderState(s string) FolderState {
+	states := []FolderState{StateUninitialized, StateInitialized, StateInitialSync, StateAutoRecovery, StateNormal, StateInError, StateDisabled}
+	if n, err := strconv.Atoi(s); err == nil {
+		// [MS-DFSRH] numeric replicated folder states
+		if n >= 0 && n < len(states)-1 {
+			return states[n]
+		}
+		return StateUnknown
+	}
+	for _, state := range states {
+		if strings.EqualFold(s, string(state)) {
+			return state
+		}
+	}
+	return StateUnknown
+}
+
+func eventSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "error", "1":
+		return SeverityError
+	case "warning", "2":
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+func trimGUID(s string) string {
+	return strings.Trim(s, "{}")
+}
+
+// node is a generic XML element with lower-case names.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+func decode(r io.Reader) (*node, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil // Reports are returned as UTF-16 BSTRs and converted to UTF-8 before parsing
+	}
+
+	var (
+		root  *node
+		stack []*node
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &ParseError{Err: err}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{
+				name:  strings.ToLower(t.Name.Local),
+				attrs: make(map[string]string, len(t.Attr)),
+			}
+			for _, a := range t.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, ErrEmptyReport
+	}
+	return root, nil
+}
+
+// attr returns the value of the first attribute or child element that is
+// present with one of the given names.
+func (n *node) attr(names ...string) string {
+	for _, name := range names {
+		if v, ok := n.attrs[name]; ok {
+			return strings.TrimSpace(v)
+		}
+		for _, c := range n.children {
+			if c.name == name && len(c.children) == 0 {
+				return strings.TrimSpace(c.text)
+			}
+		}
+	}
+	return ""
+}
+
+func (n *node) int(names ...string) int64 {
+	v, _ := strconv.ParseInt(n.attr(names...), 10, 64)
+	return v
+}
+
+func (n *node) time(names ...string) time.Time {
+	v := n.attr(names...)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// child returns the first direct or indirect child with one of the given
+// names.
+func (n *node) child(names ...string) *node {
+	for _, c := range n.children {
+		if c.is(names...) {
+			return c
+		}
+		if found := c.child(names...); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// descendants returns all of the descendants with one of the given names.
+// Descendants of matching nodes are not searched.
+func (n *node) descendants(names ...string) (nodes []*node) {
+	for _, c := range n.children {
+		if c.is(names...) {
+			nodes = append(nodes, c)
+			continue
+		}
+		nodes = append(nodes, c.descendants(names...)...)
+	}
+	return
+}
+
+func (n *node) is(names ...string) bool {
+	for _, name := range names {
+		if n.name == name {
+			return true
+		}
+	}
+	return false
+}
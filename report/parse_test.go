@@ -0,0 +1,195 @@
+package report
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, name string) *Report {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Parse(string(data))
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	return r
+}
+
+func TestParseMember(t *testing.T) {
+	r := load(t, "report.xml")
+
+	if want := time.Date(2016, 5, 4, 10, 15, 0, 0, time.UTC); !r.Generated.Equal(want) {
+		t.Errorf("Generated = %v, want %v", r.Generated, want)
+	}
+
+	want := Member{
+		Name:         "FS1",
+		ID:           "{6E5E3C43-5B8F-4B4C-9D54-0F5D2A3B7A01}",
+		Domain:       "EXAMPLE",
+		Host:         "fs1.example.com",
+		Site:         "Hub",
+		OSVersion:    "6.3.9600",
+		DFSRVersion:  "6.3.9600.16384",
+		ServiceState: "Running",
+		Uptime:       24 * time.Hour,
+	}
+	if r.Member != want {
+		t.Errorf("Member = %+v, want %+v", r.Member, want)
+	}
+}
+
+func TestParseFolders(t *testing.T) {
+	r := load(t, "report.xml")
+
+	if len(r.Groups) != 1 {
+		t.Fatalf("len(Groups) = %d, want 1", len(r.Groups))
+	}
+	g := r.Groups[0]
+	if g.Name != "Data" || g.ID != "{3F2504E0-4F89-11D3-9A0C-0305E82C3301}" {
+		t.Errorf("Group = %s %s, want Data {3F2504E0-4F89-11D3-9A0C-0305E82C3301}", g.Name, g.ID)
+	}
+	if len(g.Folders) != 2 {
+		t.Fatalf("len(Folders) = %d, want 2", len(g.Folders))
+	}
+
+	tests := []struct {
+		folder   Folder
+		name     string
+		state    FolderState
+		rootPath string
+		staging  int64
+		usage    int64
+	}{
+		{g.Folders[0], "Share", StateNormal, `D:\Share`, 4096, 120},
+		{g.Folders[1], "Archive", StateInError, `E:\Archive`, 0, 0},
+	}
+	for _, tt := range tests {
+		f := tt.folder
+		if f.Name != tt.name || f.State != tt.state || f.RootPath != tt.rootPath || f.StagingQuota != tt.staging || f.StagingUsage != tt.usage {
+			t.Errorf("Folder = %s %s %s %d/%d, want %s %s %s %d/%d", f.Name, f.State, f.RootPath, f.StagingUsage, f.StagingQuota, tt.name, tt.state, tt.rootPath, tt.usage, tt.staging)
+		}
+	}
+
+	if f := r.Folder("3f2504e0-4f89-11d3-9a0c-0305e82c3303"); f == nil || f.Name != "Archive" {
+		t.Errorf("Folder lookup by GUID failed: %+v", f)
+	}
+	if f := r.Folder("{00000000-0000-0000-0000-000000000000}"); f != nil {
+		t.Errorf("Folder lookup of unknown GUID = %+v, want nil", f)
+	}
+}
+
+func TestParseBacklogs(t *testing.T) {
+	r := load(t, "report.xml")
+	f := r.Folder("{3F2504E0-4F89-11D3-9A0C-0305E82C3302}")
+	if f == nil {
+		t.Fatal("Share folder not found")
+	}
+	if len(f.Backlogs) != 2 {
+		t.Fatalf("len(Backlogs) = %d, want 2", len(f.Backlogs))
+	}
+
+	b := f.Backlogs[0]
+	if b.Member != "FS2" || b.Count != 12 || len(b.Files) != 2 {
+		t.Errorf("Backlog = %s %d with %d files, want FS2 12 with 2 files", b.Member, b.Count, len(b.Files))
+	}
+	file := b.Files[0]
+	if file.Name != "a.txt" || file.Path != `D:\Share\a.txt` || file.Size != 1024 || file.GVSN == "" {
+		t.Errorf("File = %+v", file)
+	}
+	if want := time.Date(2016, 5, 4, 10, 5, 0, 0, time.UTC); !b.Files[1].Modified.Equal(want) {
+		t.Errorf("Modified = %v, want %v", b.Files[1].Modified, want)
+	}
+
+	// Without a count the backlog is the number of files listed
+	if b := f.Backlogs[1]; b.Member != "FS3" || b.Count != 1 {
+		t.Errorf("Backlog = %s %d, want FS3 1", b.Member, b.Count)
+	}
+
+	if sum := f.Sum(); sum != 13 {
+		t.Errorf("Sum = %d, want 13", sum)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	r := load(t, "report.xml")
+
+	if len(r.Events) != 3 {
+		t.Fatalf("len(Events) = %d, want 3", len(r.Events))
+	}
+
+	errs := r.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("len(Errors) = %d, want 1", len(errs))
+	}
+	if e := errs[0]; e.ID != 4012 || e.Group != "Data" || e.Folder != "Archive" || e.Source != "DFSR" || e.Message == "" {
+		t.Errorf("Error = %+v", e)
+	}
+
+	warnings := r.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("len(Warnings) = %d, want 1", len(warnings))
+	}
+	if e := warnings[0]; e.ID != 2213 || e.Message != "The DFS Replication service stopped replication on volume E:." {
+		t.Errorf("Warning = %+v", e)
+	}
+
+	if e := r.Events[2]; e.Severity != SeverityInformation || e.Message != "Initial replication completed." {
+		t.Errorf("Event = %+v", e)
+	}
+}
+
+func TestParseElements(t *testing.T) {
+	r := load(t, "elements.xml")
+
+	if r.Member.Name != "FS2" || r.Member.Host != "fs2.example.com" || r.Member.Site != "Branch" || r.Member.ServiceState != "Running" {
+		t.Errorf("Member = %+v", r.Member)
+	}
+	if want := time.Date(2016, 5, 4, 10, 15, 0, 0, time.UTC); !r.Generated.Equal(want) {
+		t.Errorf("Generated = %v, want %v", r.Generated, want)
+	}
+	if len(r.Groups) != 1 || r.Groups[0].Name != "Data" || len(r.Groups[0].Folders) != 1 {
+		t.Fatalf("Groups = %+v", r.Groups)
+	}
+	f := r.Groups[0].Folders[0]
+	if f.Name != "Share" || f.State != StateInitialSync || f.StagingQuota != 4096 || f.StagingUsage != 64 {
+		t.Errorf("Folder = %+v", f)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(""); !errors.Is(err, ErrEmptyReport) {
+		t.Errorf("Parse of empty report: %v, want ErrEmptyReport", err)
+	}
+
+	var perr *ParseError
+	if _, err := Parse("<report><member></report"); !errors.As(err, &perr) {
+		t.Errorf("Parse of malformed report: %v, want ParseError", err)
+	}
+}
+
+func TestFolderState(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FolderState
+	}{
+		{"0", StateUninitialized},
+		{"4", StateNormal},
+		{"5", StateInError},
+		{"6", StateUnknown},
+		{"-1", StateUnknown},
+		{"normal", StateNormal},
+		{"AutoRecovery", StateAutoRecovery},
+		{"bogus", StateUnknown},
+	}
+	for _, tt := range tests {
+		if got := folderState(tt.in); got != tt.want {
+			t.Errorf("folderState(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
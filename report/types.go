@@ -0,0 +1,101 @@
+package report
+
+import "time"
+
+// Report is a parsed DFSR health report.
+type Report struct {
+	Generated time.Time // Time at which the member generated the report
+	Member    Member
+	Groups    []Group
+	Events    []Event
+}
+
+// Member describes the DFSR member that produced a report.
+type Member struct {
+	Name         string
+	ID           string // GUID in canonical string form
+	Domain       string
+	Host         string // Fully qualified domain name
+	Site         string
+	OSVersion    string
+	DFSRVersion  string
+	ServiceState string
+	Uptime       time.Duration
+}
+
+// Group describes the state of a replication group on the reporting member.
+type Group struct {
+	Name    string
+	ID      string // GUID in canonical string form
+	Folders []Folder
+}
+
+// Folder describes the state of a replicated folder on the reporting member.
+type Folder struct {
+	Name          string
+	ID            string // GUID in canonical string form
+	State         FolderState
+	RootPath      string
+	StagingPath   string
+	StagingQuota  int64 // Staging quota in megabytes
+	StagingUsage  int64 // Staging usage in megabytes
+	ConflictQuota int64 // Conflict and deleted quota in megabytes
+	ConflictUsage int64 // Conflict and deleted usage in megabytes
+	Backlogs      []Backlog
+}
+
+// FolderState is the replication state of a replicated folder.
+type FolderState string
+
+// Replicated folder states.
+const (
+	StateUninitialized FolderState = "Uninitialized"
+	StateInitialized   FolderState = "Initialized"
+	StateInitialSync   FolderState = "InitialSync"
+	StateAutoRecovery  FolderState = "AutoRecovery"
+	StateNormal        FolderState = "Normal"
+	StateInError       FolderState = "InError"
+	StateDisabled      FolderState = "Disabled"
+	StateUnknown       FolderState = "Unknown"
+)
+
+// Backlog describes the files that the reporting member has yet to send to
+// the reference member of a replicated folder.
+type Backlog struct {
+	Member   string // Name of the reference member
+	MemberID string // GUID of the reference member in canonical string form
+	Count    int
+	Files    []File // Backlogged files. Only present when files are requested and may be truncated.
+}
+
+// File describes a backlogged file.
+type File struct {
+	Name     string
+	Path     string
+	UID      string
+	GVSN     string
+	Size     int64
+	Modified time.Time
+}
+
+// Severity is the severity of an event.
+type Severity string
+
+// Event severities.
+const (
+	SeverityError       Severity = "Error"
+	SeverityWarning     Severity = "Warning"
+	SeverityInformation Severity = "Information"
+)
+
+// Event describes an error, warning or informational event recorded by the
+// reporting member.
+type Event struct {
+	ID       int
+	Severity Severity
+	Time     time.Time
+	Source   string
+	Group    string // Name of the affected replication group, if any
+	Folder   string // Name of the affected replicated folder, if any
+	Message  string
+}
@@ -15,6 +15,7 @@ const (
 	ErrGeneric
 	ErrConfigInitFailure
 	ErrBacklogInitFailure
+	ErrAlertInitFailure
 )
 
 // Event constants
@@ -22,4 +23,9 @@ const (
 	EventInitProgress = iota + 1
 	EventInitComplete
 	EventInitFailure
+	EventAlertFiring
+	EventAlertResolved
+	EventConfigChange
+	EventConfigGroupFailure
+	EventConfigGroupRecovered
 )
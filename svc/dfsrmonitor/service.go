@@ -6,13 +6,21 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
-	"strconv"
+	"strings"
 	"time"
 
+	"gopkg.in/dfsr.v0/alert"
 	"gopkg.in/dfsr.v0/config"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/fileconfig"
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/helper"
 	"gopkg.in/dfsr.v0/monitor"
+	"gopkg.in/dfsr.v0/monitor/consumer/prometheusconsumer"
 	"gopkg.in/dfsr.v0/monitor/consumer/stathatconsumer"
+	"gopkg.in/dfsr.v0/topology"
 
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/debug"
@@ -27,6 +35,16 @@ const acceptedCmds = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndCon
 
 const updateChanSize = 16
 
+// configMonitor is a source of DFSR configuration that can be started and
+// updated.
+type configMonitor interface {
+	monitor.Source
+	Start() error
+	Update()
+	WaitReady(ctx context.Context) error
+	Close()
+}
+
 func (m *dfsrmonitor) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (ssec bool, errno uint32) {
 	changes <- svc.Status{State: svc.StartPending}
 
@@ -47,18 +65,63 @@ func (m *dfsrmonitor) Execute(args []string, r <-chan svc.ChangeRequest, changes
 
 	// Step 2: Create and start configuration monitor
 	elog.Info(EventInitProgress, "Creating configuration monitor.")
-	cfg := config.NewDomainMonitor(settings.Domain, settings.ConfigPollingInterval)
+	mode, err := settings.Mode()
+	if err != nil {
+		elog.Error(EventInitFailure, fmt.Sprintf("Configuration initialization failure: %v", err))
+		return true, ErrConfigInitFailure
+	}
+	open := settings.Open()
+	var cfg configMonitor
+	switch {
+	case settings.TopologyFile != "":
+		cfg = fileconfig.New(settings.TopologyFile, settings.ConfigPollingInterval)
+	case settings.MultiDomain():
+		cfg = config.NewForestMonitorWithConfig(settings.Domains(), settings.ConfigPollingInterval, config.ForestMonitorConfig{
+			Open:    open,
+			Timeout: settings.ConfigTimeout,
+			Mode:    mode,
+		})
+	default:
+		var openDomain func() (directory.Directory, error)
+		if open != nil {
+			openDomain = func() (directory.Directory, error) { return open("") }
+		}
+		cfg = config.NewDomainMonitorWithConfig(settings.Domain, settings.ConfigPollingInterval, config.DomainMonitorConfig{
+			Open:    openDomain,
+			Timeout: settings.ConfigTimeout,
+			Mode:    mode,
+		})
+	}
 	if err := cfg.Start(); err != nil {
 		elog.Error(EventInitFailure, fmt.Sprintf("Configuration initialization failure: %v", err))
 		return true, ErrConfigInitFailure
 	}
 	defer cfg.Close()
 
+	switch c := cfg.(type) {
+	case *config.DomainMonitor:
+		go watchDomainUpdates(c.Listen())
+	case *config.ForestMonitor:
+		go watchDomainUpdates(c.Listen())
+	case *fileconfig.Monitor:
+		go func(updates <-chan fileconfig.Update) {
+			for update := range updates {
+				logChanges(update.Changes)
+			}
+		}(c.Listen())
+	}
+
 	cfg.Update()
-	if err := cfg.WaitReady(); err != nil { // TODO: Support some sort of timeout
+	if err := waitReady(cfg, settings.ConfigTimeout); err != nil {
 		elog.Error(EventInitFailure, fmt.Sprintf("Configuration initialization failure: %v", err))
 		return true, ErrConfigInitFailure
 	}
+	if forest, ok := cfg.(*config.ForestMonitor); ok {
+		elog.Info(EventInitProgress, fmt.Sprintf("Monitoring domains: %s", strings.Join(forest.Domains(), "; ")))
+		for domain, err := range forest.Failures() {
+			elog.Warning(EventInitProgress, fmt.Sprintf("Configuration unavailable for domain %s: %v", domain, err))
+		}
+	}
 
 	// Step 3: Create backlog monitor
 	elog.Info(EventInitProgress, "Creating backlog monitor.")
@@ -66,9 +129,36 @@ func (m *dfsrmonitor) Execute(args []string, r <-chan svc.ChangeRequest, changes
 	monChan := mon.Listen(updateChanSize)
 
 	// Step 4: Create backlog consumers
+	rules := alert.DefaultRules
+	if settings.AlertRules != "" {
+		loaded, err := alert.LoadRules(settings.AlertRules)
+		if err != nil {
+			elog.Error(EventInitFailure, fmt.Sprintf("Alerting rules initialization failure: %v", err))
+			return true, ErrAlertInitFailure
+		}
+		rules = loaded
+	}
+	alerts, err := alert.New(rules, mon.Listen(updateChanSize))
+	if err != nil {
+		elog.Error(EventInitFailure, fmt.Sprintf("Alerting rules initialization failure: %v", err))
+		return true, ErrAlertInitFailure
+	}
+	go watchAlerts(alerts.Listen())
+
 	if settings.StatHatKey != "" {
 		stathatconsumer.New(settings.StatHatKey, settings.StatHatFormat, mon.Listen(updateChanSize))
 	}
+	if settings.PrometheusAddress != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", prometheusconsumer.New(mon.Listen(updateChanSize)))
+		server := &http.Server{Addr: settings.PrometheusAddress, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				elog.Error(1, fmt.Sprintf("Prometheus endpoint failure: %v", err))
+			}
+		}()
+		defer server.Close()
+	}
 
 	// Step 5: Start backlog monitor
 	if err := mon.Start(); err != nil {
@@ -152,32 +242,85 @@ func runService(env *Environment) {
 }
 
 func watchUpdate(update *monitor.Update) {
-	var (
-		i, size = 0, update.Size()
-		len     = len(strconv.Itoa(size))
-	)
+	var backlogged, failed int
 
 	elog.Info(1, fmt.Sprintf("Polling started at %v", update.Start()))
 	for backlog := range update.Listen() {
-		i++
-		if backlog.Err != nil {
-			if !isCancellationErr(backlog.Err) {
-				elog.Warning(1, fmt.Sprintf("[%*d/%*d] %s backlog from %s to %s: %v", len, i, len, size, backlog.Group.Name, backlog.From, backlog.To, backlog.Err))
+		switch {
+		case backlog.Err != nil:
+			if !helper.IsCancellationErr(backlog.Err) {
+				failed++
 			}
-			continue
+		case !backlog.IsZero():
+			backlogged++
 		}
-		if !backlog.IsZero() {
-			elog.Info(1, fmt.Sprintf("[%*d/%*d] %s backlog from %s to %s: %v", len, i, len, size, backlog.Group.Name, backlog.From, backlog.To, backlog.Sum()))
+	}
+	elog.Info(1, fmt.Sprintf("Polling finished at %v. Total wall time: %v. Connections: %d, backlogged: %d, failed: %d", update.End(), update.Duration(), update.Size(), backlogged, failed))
+}
+
+// waitReady waits for cfg to retrieve its initial configuration. If timeout is
+// non-zero it gives up after that much time has elapsed.
+func waitReady(cfg configMonitor, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return cfg.WaitReady(ctx)
+}
+
+// watchDomainUpdates logs configuration changes and group failures of the
+// domain configuration updates it receives.
+func watchDomainUpdates(updates <-chan config.DomainUpdate) {
+	failures := make(groupFailures)
+	for update := range updates {
+		logChanges(update.Changes)
+		if update.Err == nil {
+			failures.log(update.Domain)
 		}
 	}
-	elog.Info(1, fmt.Sprintf("Polling finished at %v. Total wall time: %v", update.End(), update.Duration()))
 }
 
-func isCancellationErr(err error) bool {
-	switch err {
-	case context.Canceled, context.DeadlineExceeded:
-		return true
-	default:
-		return false
+// logChanges logs changes to the DFSR configuration to the event log.
+func logChanges(changes []topology.Change) {
+	for _, change := range changes {
+		elog.Info(EventConfigChange, fmt.Sprintf("Configuration change: %s", change))
+	}
+}
+
+// groupFailures is the set of replication groups of each domain whose
+// configuration could not be retrieved, keyed by domain and then group name.
+type groupFailures map[string]map[string]bool
+
+// log logs replication groups of domain whose configuration retrieval started
+// failing or recovered since the previous update of the domain to the event
+// log. Backlogs for the failed groups are not polled.
+func (gf groupFailures) log(domain *core.Domain) {
+	previous := gf[domain.DN]
+	current := make(map[string]bool)
+	for _, group := range domain.Failed() {
+		current[group.Name] = true
+		if !previous[group.Name] {
+			elog.Warning(EventConfigGroupFailure, fmt.Sprintf("Configuration retrieval failed for replication group %s in %s: %v", group.Name, domain.DN, group.Err))
+		}
+	}
+	for name := range previous {
+		if !current[name] {
+			elog.Info(EventConfigGroupRecovered, fmt.Sprintf("Configuration retrieval recovered for replication group %s in %s", name, domain.DN))
+		}
+	}
+	gf[domain.DN] = current
+}
+
+// watchAlerts logs alerts to the event log when they fire or are resolved.
+func watchAlerts(alerts <-chan alert.Alert) {
+	for a := range alerts {
+		switch a.State {
+		case alert.Firing:
+			elog.Warning(EventAlertFiring, fmt.Sprintf("Alert firing: %s", a))
+		case alert.Resolved:
+			elog.Info(EventAlertResolved, fmt.Sprintf("Alert resolved: %s", a))
+		}
 	}
 }
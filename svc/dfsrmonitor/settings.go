@@ -5,25 +5,39 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gentlemanautomaton/bindflag"
+	"gopkg.in/dfsr.v0/config/directory"
+	"gopkg.in/dfsr.v0/config/directory/ldapdir"
+	"gopkg.in/dfsr.v0/config/globalsettings"
 )
 
 // Settings represents a set of DFSR monitor service configuration settings
 type Settings struct {
-	Domain                 string
+	Domain                 string // Comma-separated list of domains
+	Forest                 bool
 	ConfigPollingInterval  time.Duration
+	ConfigTimeout          time.Duration
+	ConfigMode             string
+	LDAP                   string
+	LDAPUser               string
 	BacklogPollingInterval time.Duration
 	VectorCacheDuration    time.Duration
 	Limit                  uint
 	StatHatKey             string
 	StatHatFormat          string
+	PrometheusAddress      string
+	TopologyFile           string
+	AlertRules             string
 }
 
 // DefaultSettings is the default set of DFSR monitor settings.
 var DefaultSettings = Settings{
 	ConfigPollingInterval:  15 * time.Minute,
+	ConfigTimeout:          5 * time.Minute,
 	BacklogPollingInterval: 5 * time.Minute,
 	VectorCacheDuration:    30 * time.Second,
 	Limit:                  1,
@@ -31,13 +45,21 @@ var DefaultSettings = Settings{
 
 // Bind will link the settings to the provided flag set.
 func (s *Settings) Bind(fs *flag.FlagSet) {
-	fs.Var(bindflag.String(&s.Domain), "domain", "AD domain to monitor (will autodetect if not provided)")
+	fs.Var(bindflag.String(&s.Domain), "domain", "AD domain or comma-separated list of domains to monitor (will autodetect if not provided)")
+	fs.Var(bindflag.Bool(&s.Forest), "forest", "monitor every domain in the forest, or only those listed by -domain")
 	fs.Var(bindflag.Duration(&s.ConfigPollingInterval), "cpi", "configuration polling interval")
+	fs.Var(bindflag.Duration(&s.ConfigTimeout), "cto", "configuration retrieval timeout (0 for none)")
+	fs.Var(bindflag.String(&s.ConfigMode), "mode", "configuration retrieval mode: auto, objects or search (search requires -ldap)")
+	fs.Var(bindflag.String(&s.LDAP), "ldap", "URL of an LDAP server to query instead of using ADSI, such as ldap://dc1.example.com (each domain of a forest is queried through its DNS name)")
+	fs.Var(bindflag.String(&s.LDAPUser), "ldapuser", "bind DN or user principal name for LDAP (password is read from LDAP_PASSWORD)")
 	fs.Var(bindflag.Duration(&s.BacklogPollingInterval), "bpi", "backlog polling interval")
 	fs.Var(bindflag.Duration(&s.VectorCacheDuration), "cache", "vector cache duration")
 	fs.Var(bindflag.Uint(&s.Limit), "limit", "maximum number of queries per server")
 	fs.Var(bindflag.String(&s.StatHatKey), "shk", "StatHat ezkey for StatHat reporting")
 	fs.Var(bindflag.String(&s.StatHatFormat), "shf", "StatHat name format in fmt style")
+	fs.Var(bindflag.String(&s.PrometheusAddress), "prom", "listen address for the Prometheus /metrics endpoint")
+	fs.Var(bindflag.String(&s.TopologyFile), "topology", "JSON or YAML topology file to use instead of Active Directory")
+	fs.Var(bindflag.String(&s.AlertRules), "rules", "JSON or YAML alerting rules file (uses default rules if not provided)")
 }
 
 // Parse parses the given argument list and applies the specified values.
@@ -47,15 +69,75 @@ func (s *Settings) Parse(args []string, errorHandling flag.ErrorHandling) (err e
 	return fs.Parse(args)
 }
 
+// Domains returns the list of domains to monitor. It returns nil if no domain
+// was provided.
+func (s *Settings) Domains() (domains []string) {
+	for _, domain := range strings.Split(s.Domain, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return
+}
+
+// MultiDomain returns true if the settings call for a monitor that spans more
+// than one domain.
+func (s *Settings) MultiDomain() bool {
+	return s.Forest || len(s.Domains()) > 1
+}
+
+// Mode returns the configuration retrieval mode.
+func (s *Settings) Mode() (mode globalsettings.Mode, err error) {
+	err = mode.UnmarshalText([]byte(s.ConfigMode))
+	return
+}
+
+// Open returns a function that opens the directory that configuration is
+// retrieved from for a domain, or nil if Active Directory should be queried
+// through ADSI. The LDAP server of each domain is reached through the DNS name
+// of the domain on the scheme and port of the LDAP URL. The LDAP URL itself is
+// used when the domain is empty.
+func (s *Settings) Open() func(domain string) (directory.Directory, error) {
+	if s.LDAP == "" {
+		return nil
+	}
+	addr, user := s.LDAP, s.LDAPUser
+	return func(domain string) (directory.Directory, error) {
+		url, err := ldapdir.DomainURL(addr, domain)
+		if err != nil {
+			return nil, err
+		}
+		return ldapdir.Dial(url, ldapdir.Config{
+			BindDN:   user,
+			Password: os.Getenv("LDAP_PASSWORD"),
+		})
+	}
+}
+
 // Args returns the current settings as a set of command line arguments that can
 // be passed back into the service.
 func (s *Settings) Args() (args []string) {
 	if s.Domain != "" {
 		args = append(args, makeArg("domain", s.Domain))
 	}
+	if s.Forest {
+		args = append(args, makeArg("forest", "true"))
+	}
 	if s.ConfigPollingInterval != time.Duration(0) {
 		args = append(args, makeArg("cpi", s.ConfigPollingInterval.String()))
 	}
+	if s.ConfigTimeout != time.Duration(0) {
+		args = append(args, makeArg("cto", s.ConfigTimeout.String()))
+	}
+	if s.ConfigMode != "" {
+		args = append(args, makeArg("mode", s.ConfigMode))
+	}
+	if s.LDAP != "" {
+		args = append(args, makeArg("ldap", s.LDAP))
+	}
+	if s.LDAPUser != "" {
+		args = append(args, makeArg("ldapuser", s.LDAPUser))
+	}
 	if s.BacklogPollingInterval != time.Duration(0) {
 		args = append(args, makeArg("bpi", s.BacklogPollingInterval.String()))
 	}
@@ -71,5 +153,14 @@ func (s *Settings) Args() (args []string) {
 	if s.StatHatFormat != "" {
 		args = append(args, makeArg("shf", s.StatHatFormat))
 	}
+	if s.PrometheusAddress != "" {
+		args = append(args, makeArg("prom", s.PrometheusAddress))
+	}
+	if s.TopologyFile != "" {
+		args = append(args, makeArg("topology", s.TopologyFile))
+	}
+	if s.AlertRules != "" {
+		args = append(args, makeArg("rules", s.AlertRules))
+	}
 	return
 }
@@ -0,0 +1,93 @@
+package topology
+
+import (
+	"sort"
+
+	"gopkg.in/dfsr.v0/core"
+)
+
+// Link is a one-way connection from one member to another.
+type Link struct {
+	From string
+	To   string
+}
+
+// Analysis is the result of analyzing the topology of a replication group.
+// Members are identified by name.
+type Analysis struct {
+	Group              string
+	NoInbound          []string   // Members without inbound connections, which never receive changes
+	NoOutbound         []string   // Members without outbound connections, whose changes are never replicated
+	OneWay             []Link     // Connections without a connection in the reverse direction
+	Islands            [][]string // Sets of members that are disconnected from each other, if there is more than one
+	ArticulationPoints []string   // Members whose loss would partition the group
+}
+
+// OK reports whether the analysis found no problems.
+func (a *Analysis) OK() bool {
+	return len(a.NoInbound) == 0 && len(a.NoOutbound) == 0 && len(a.OneWay) == 0 && len(a.Islands) == 0 && len(a.ArticulationPoints) == 0
+}
+
+// Analyze analyzes the topology of the given replication group. Groups with
+// a single member are not considered to have any problems.
+func Analyze(group *core.Group) (analysis Analysis) {
+	analysis.Group = group.Name
+	if len(group.Members) < 2 {
+		return
+	}
+
+	g := newGraph(group)
+
+	for v, name := range g.names {
+		if len(g.in[v]) == 0 {
+			analysis.NoInbound = append(analysis.NoInbound, name)
+		}
+		if len(g.out[v]) == 0 {
+			analysis.NoOutbound = append(analysis.NoOutbound, name)
+		}
+		for w := range g.out[v] {
+			if !g.out[w][v] {
+				analysis.OneWay = append(analysis.OneWay, Link{From: name, To: g.names[w]})
+			}
+		}
+	}
+
+	if components := g.components(); len(components) > 1 {
+		for _, component := range components {
+			analysis.Islands = append(analysis.Islands, g.sortedNames(component))
+		}
+		sort.Slice(analysis.Islands, func(i, j int) bool {
+			return analysis.Islands[i][0] < analysis.Islands[j][0]
+		})
+	}
+
+	analysis.ArticulationPoints = g.sortedNames(g.articulationPoints())
+
+	sort.Strings(analysis.NoInbound)
+	sort.Strings(analysis.NoOutbound)
+	sort.Slice(analysis.OneWay, func(i, j int) bool {
+		if analysis.OneWay[i].From != analysis.OneWay[j].From {
+			return analysis.OneWay[i].From < analysis.OneWay[j].From
+		}
+		return analysis.OneWay[i].To < analysis.OneWay[j].To
+	})
+
+	return
+}
+
+// AnalyzeDomain analyzes the topology of every replication group in the
+// given domain.
+func AnalyzeDomain(domain *core.Domain) (output []Analysis) {
+	for g := range domain.Groups {
+		output = append(output, Analyze(&domain.Groups[g]))
+	}
+	return
+}
+
+func (g *graph) sortedNames(vertices []int) (names []string) {
+	for _, v := range vertices {
+		names = append(names, g.names[v])
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,243 @@
+package topology
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+
+	"github.com/go-ole/go-ole"
+)
+
+// ChangeKind is the kind of a configuration change.
+type ChangeKind int
+
+// Change kinds.
+const (
+	GroupAdded ChangeKind = iota + 1
+	GroupRemoved
+	FolderAdded
+	FolderRemoved
+	MemberAdded
+	MemberRemoved
+	ConnectionAdded
+	ConnectionRemoved
+	ConnectionEnabled
+	ConnectionDisabled
+	HostChanged
+)
+
+// String returns a string representation of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case GroupAdded:
+		return "group added"
+	case GroupRemoved:
+		return "group removed"
+	case FolderAdded:
+		return "folder added"
+	case FolderRemoved:
+		return "folder removed"
+	case MemberAdded:
+		return "member added"
+	case MemberRemoved:
+		return "member removed"
+	case ConnectionAdded:
+		return "connection added"
+	case ConnectionRemoved:
+		return "connection removed"
+	case ConnectionEnabled:
+		return "connection enabled"
+	case ConnectionDisabled:
+		return "connection disabled"
+	case HostChanged:
+		return "host changed"
+	default:
+		return fmt.Sprintf("change(%d)", int(k))
+	}
+}
+
+// Change describes a single difference between two domain configurations.
+// Objects are identified by name.
+type Change struct {
+	Kind       ChangeKind
+	Group      string
+	Folder     string // Set for folder changes
+	Member     string // Set for member, connection and host changes
+	Connection string // Set for connection changes and host changes of connections
+	OldHost    string // Set for host changes
+	NewHost    string // Set for host changes
+}
+
+// String returns a description of the change.
+func (c Change) String() string {
+	var subject string
+	switch c.Kind {
+	case GroupAdded, GroupRemoved:
+		return fmt.Sprintf("%s: %s", c.Group, c.Kind)
+	case FolderAdded, FolderRemoved:
+		subject = c.Folder
+	case MemberAdded, MemberRemoved:
+		subject = c.Member
+	case HostChanged:
+		subject = c.Member
+		if c.Connection != "" {
+			subject = fmt.Sprintf("%s/%s", c.Member, c.Connection)
+		}
+		return fmt.Sprintf("%s: %s: %s from %s to %s", c.Group, subject, c.Kind, c.OldHost, c.NewHost)
+	default:
+		subject = fmt.Sprintf("%s/%s", c.Member, c.Connection)
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Group, subject, c.Kind)
+}
+
+// Diff compares two domain configurations and returns the changes that were
+// made to get from previous to current. Objects are matched by ID, or by name
+// when they do not have one.
+//
+// The contents of groups whose configuration could not be retrieved in either
+// domain are not compared.
+//
+// If either domain is nil, Diff returns nil.
+func Diff(previous, current *core.Domain) (changes []Change) {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	oldGroups := make(map[string]*core.Group, len(previous.Groups))
+	for g := range previous.Groups {
+		group := &previous.Groups[g]
+		oldGroups[key(group.Name, group.ID)] = group
+	}
+
+	matched := make(map[string]bool, len(current.Groups))
+	for g := range current.Groups {
+		group := &current.Groups[g]
+		k := key(group.Name, group.ID)
+		matched[k] = true
+		if old, ok := oldGroups[k]; ok {
+			if old.Err == nil && group.Err == nil {
+				changes = append(changes, diffGroup(old, group)...)
+			}
+		} else {
+			changes = append(changes, Change{Kind: GroupAdded, Group: group.Name})
+		}
+	}
+
+	for g := range previous.Groups {
+		group := &previous.Groups[g]
+		if !matched[key(group.Name, group.ID)] {
+			changes = append(changes, Change{Kind: GroupRemoved, Group: group.Name})
+		}
+	}
+
+	return
+}
+
+func diffGroup(previous, current *core.Group) (changes []Change) {
+	oldFolders := make(map[string]bool, len(previous.Folders))
+	for f := range previous.Folders {
+		oldFolders[key(previous.Folders[f].Name, previous.Folders[f].ID)] = true
+	}
+	newFolders := make(map[string]bool, len(current.Folders))
+	for f := range current.Folders {
+		folder := &current.Folders[f]
+		k := key(folder.Name, folder.ID)
+		newFolders[k] = true
+		if !oldFolders[k] {
+			changes = append(changes, Change{Kind: FolderAdded, Group: current.Name, Folder: folder.Name})
+		}
+	}
+	for f := range previous.Folders {
+		folder := &previous.Folders[f]
+		if !newFolders[key(folder.Name, folder.ID)] {
+			changes = append(changes, Change{Kind: FolderRemoved, Group: current.Name, Folder: folder.Name})
+		}
+	}
+
+	oldMembers := make(map[string]*core.Member, len(previous.Members))
+	for m := range previous.Members {
+		member := &previous.Members[m]
+		oldMembers[key(member.Name, member.ID)] = member
+	}
+	matched := make(map[string]bool, len(current.Members))
+	for m := range current.Members {
+		member := &current.Members[m]
+		k := key(member.Name, member.ID)
+		matched[k] = true
+		if old, ok := oldMembers[k]; ok {
+			changes = append(changes, diffMember(current.Name, old, member)...)
+		} else {
+			changes = append(changes, Change{Kind: MemberAdded, Group: current.Name, Member: member.Name})
+		}
+	}
+	for m := range previous.Members {
+		member := &previous.Members[m]
+		if !matched[key(member.Name, member.ID)] {
+			changes = append(changes, Change{Kind: MemberRemoved, Group: current.Name, Member: member.Name})
+		}
+	}
+
+	return
+}
+
+func diffMember(group string, previous, current *core.Member) (changes []Change) {
+	if !strings.EqualFold(previous.Computer.Host, current.Computer.Host) {
+		changes = append(changes, Change{
+			Kind:    HostChanged,
+			Group:   group,
+			Member:  current.Name,
+			OldHost: previous.Computer.Host,
+			NewHost: current.Computer.Host,
+		})
+	}
+
+	oldConns := make(map[string]*core.Connection, len(previous.Connections))
+	for c := range previous.Connections {
+		conn := &previous.Connections[c]
+		oldConns[key(conn.Name, conn.ID)] = conn
+	}
+	matched := make(map[string]bool, len(current.Connections))
+	for c := range current.Connections {
+		conn := &current.Connections[c]
+		k := key(conn.Name, conn.ID)
+		matched[k] = true
+		change := Change{Group: group, Member: current.Name, Connection: conn.Name}
+		old, ok := oldConns[k]
+		if !ok {
+			change.Kind = ConnectionAdded
+			changes = append(changes, change)
+			continue
+		}
+		if old.Enabled != conn.Enabled {
+			change.Kind = ConnectionDisabled
+			if conn.Enabled {
+				change.Kind = ConnectionEnabled
+			}
+			changes = append(changes, change)
+		}
+		if !strings.EqualFold(old.Computer.Host, conn.Computer.Host) {
+			change.Kind = HostChanged
+			change.OldHost = old.Computer.Host
+			change.NewHost = conn.Computer.Host
+			changes = append(changes, change)
+		}
+	}
+	for c := range previous.Connections {
+		conn := &previous.Connections[c]
+		if !matched[key(conn.Name, conn.ID)] {
+			changes = append(changes, Change{Kind: ConnectionRemoved, Group: group, Member: current.Name, Connection: conn.Name})
+		}
+	}
+
+	return
+}
+
+// key returns a key that identifies an object by its ID, or by its name if it
+// does not have one.
+func key(name string, id *ole.GUID) string {
+	if id == nil {
+		return "name:" + strings.ToLower(name)
+	}
+	return "id:" + strings.ToUpper(id.String())
+}
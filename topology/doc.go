@@ -0,0 +1,17 @@
+// Package topology analyzes the connection topology of DFSR replication
+// groups.
+//
+// The analysis looks for misconfigurations that prevent data from converging
+// and that are otherwise usually discovered only after it stops converging:
+// members without inbound or outbound connections, one-way connections,
+// members that are split into disconnected islands and members whose loss
+// would partition a group.
+//
+// Only enabled connections are considered.
+//
+//   for _, analysis := range topology.AnalyzeDomain(&domain) {
+//     if !analysis.OK() {
+//       fmt.Println(analysis.Group)
+//     }
+//   }
+package topology
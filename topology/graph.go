@@ -0,0 +1,182 @@
+package topology
+
+import (
+	"strings"
+
+	"gopkg.in/dfsr.v0/core"
+)
+
+// graph is the connection graph of a replication group. Vertices are member
+// indices and edges point from the source member to the destination member.
+type graph struct {
+	names []string
+	out   []map[int]bool // Enabled outbound connections of each member
+	in    []map[int]bool // Enabled inbound connections of each member
+}
+
+func newGraph(group *core.Group) *graph {
+	n := len(group.Members)
+	g := &graph{
+		names: make([]string, n),
+		out:   make([]map[int]bool, n),
+		in:    make([]map[int]bool, n),
+	}
+	for m := range group.Members {
+		g.names[m] = memberName(&group.Members[m])
+		g.out[m] = make(map[int]bool)
+		g.in[m] = make(map[int]bool)
+	}
+	for to := range group.Members {
+		member := &group.Members[to]
+		for c := range member.Connections {
+			conn := &member.Connections[c]
+			if !conn.Enabled {
+				continue
+			}
+			from := source(group, conn)
+			if from < 0 || from == to {
+				continue
+			}
+			g.out[from][to] = true
+			g.in[to][from] = true
+		}
+	}
+	return g
+}
+
+// neighbors returns the members that are connected to v in either direction.
+func (g *graph) neighbors(v int) (output []int) {
+	for w := range g.out[v] {
+		output = append(output, w)
+	}
+	for w := range g.in[v] {
+		if !g.out[v][w] {
+			output = append(output, w)
+		}
+	}
+	return
+}
+
+// components returns the connected components of the graph when the
+// direction of connections is ignored.
+func (g *graph) components() (output [][]int) {
+	visited := make([]bool, len(g.names))
+	for v := range g.names {
+		if visited[v] {
+			continue
+		}
+		var component []int
+		stack := []int{v}
+		visited[v] = true
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, u)
+			for _, w := range g.neighbors(u) {
+				if !visited[w] {
+					visited[w] = true
+					stack = append(stack, w)
+				}
+			}
+		}
+		output = append(output, component)
+	}
+	return
+}
+
+// articulationPoints returns the members whose removal would increase the
+// number of connected components of the graph when the direction of
+// connections is ignored.
+func (g *graph) articulationPoints() (output []int) {
+	var (
+		n      = len(g.names)
+		index  = make([]int, n) // Discovery order, starting at 1
+		low    = make([]int, n)
+		parent = make([]int, n)
+		cut    = make([]bool, n)
+		next   = 1
+	)
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		children := 0
+		for _, w := range g.neighbors(v) {
+			if index[w] == 0 {
+				parent[w] = v
+				children++
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+				if parent[v] >= 0 && low[w] >= index[v] {
+					cut[v] = true
+				}
+			} else if w != parent[v] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if parent[v] < 0 && children > 1 {
+			cut[v] = true
+		}
+	}
+
+	for v := 0; v < n; v++ {
+		if index[v] == 0 {
+			parent[v] = -1
+			visit(v)
+		}
+	}
+
+	for v := 0; v < n; v++ {
+		if cut[v] {
+			output = append(output, v)
+		}
+	}
+	return
+}
+
+// source returns the index of the member that is the source of the given
+// connection, or -1 if it cannot be determined. The connections of SYSVOL
+// groups refer to the nTDSDSA object of the source domain controller instead
+// of its member, so they are matched by computer.
+func source(group *core.Group, conn *core.Connection) int {
+	for m := range group.Members {
+		member := &group.Members[m]
+		if conn.MemberDN != "" && member.DN != "" {
+			if strings.EqualFold(conn.MemberDN, member.DN) {
+				return m
+			}
+			continue
+		}
+		if conn.Computer.Host != "" && strings.EqualFold(conn.Computer.Host, member.Computer.Host) {
+			return m
+		}
+	}
+	if !group.Sysvol() {
+		return -1
+	}
+	if conn.Computer.DN != "" {
+		for m := range group.Members {
+			if strings.EqualFold(conn.Computer.DN, group.Members[m].Computer.DN) {
+				return m
+			}
+		}
+	}
+	if conn.Computer.Host != "" {
+		for m := range group.Members {
+			if strings.EqualFold(conn.Computer.Host, group.Members[m].Computer.Host) {
+				return m
+			}
+		}
+	}
+	return -1
+}
+
+func memberName(member *core.Member) string {
+	if member.Name != "" {
+		return member.Name
+	}
+	return member.Computer.Host
+}
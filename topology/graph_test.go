@@ -0,0 +1,96 @@
+package topology_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/dfsr.v0/core"
+	"gopkg.in/dfsr.v0/topology"
+)
+
+func dc(name string) core.Computer {
+	return core.Computer{
+		DN:               "CN=" + name + ",OU=Domain Controllers,DC=example,DC=com",
+		Host:             name + ".example.com",
+		DomainController: true,
+	}
+}
+
+// ntdsConnection returns a connection from the given domain controller as it
+// appears in a SYSVOL group, where the source is the domain controller's
+// nTDSDSA object rather than its member.
+func ntdsConnection(name string) core.Connection {
+	return core.Connection{
+		Name:     name,
+		MemberDN: "CN=NTDS Settings,CN=" + name + ",CN=Servers,CN=HQ,CN=Sites,CN=Configuration,DC=example,DC=com",
+		Enabled:  true,
+		Computer: core.Computer{DN: dc(name).DN},
+	}
+}
+
+func sysvolMember(name string, from ...string) core.Member {
+	member := core.Member{
+		MemberInfo: core.MemberInfo{
+			Name:     name,
+			DN:       "CN=" + name + ",CN=Topology,CN=Domain System Volume,CN=DFSR-GlobalSettings,CN=System,DC=example,DC=com",
+			Computer: dc(name),
+		},
+	}
+	for _, f := range from {
+		member.Connections = append(member.Connections, ntdsConnection(f))
+	}
+	return member
+}
+
+func TestAnalyzeSysvol(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Type  core.GroupType
+		Links map[string][]string // Members and the sources of their connections
+		Want  topology.Analysis
+	}{
+		{
+			Name:  "Chain",
+			Type:  core.GroupTypeSysvol,
+			Links: map[string][]string{"DC1": {"DC2"}, "DC2": {"DC1", "DC3"}, "DC3": {"DC2"}},
+			Want:  topology.Analysis{ArticulationPoints: []string{"DC2"}},
+		},
+		{
+			Name:  "OneWay",
+			Type:  core.GroupTypeSysvol,
+			Links: map[string][]string{"DC1": {"DC2"}, "DC2": nil},
+			Want: topology.Analysis{
+				NoInbound:  []string{"DC2"},
+				NoOutbound: []string{"DC1"},
+				OneWay:     []topology.Link{{From: "DC2", To: "DC1"}},
+			},
+		},
+		{
+			// Outside of SYSVOL a connection must name the member of its
+			// source, so none of the connections resolve.
+			Name:  "Data",
+			Type:  core.GroupTypeData,
+			Links: map[string][]string{"DC1": {"DC2"}, "DC2": {"DC1"}},
+			Want: topology.Analysis{
+				NoInbound:  []string{"DC1", "DC2"},
+				NoOutbound: []string{"DC1", "DC2"},
+				Islands:    [][]string{{"DC1"}, {"DC2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			group := core.Group{Name: "Domain System Volume", Type: tt.Type}
+			for _, name := range []string{"DC1", "DC2", "DC3"} {
+				if from, ok := tt.Links[name]; ok {
+					group.Members = append(group.Members, sysvolMember(name, from...))
+				}
+			}
+			got := topology.Analyze(&group)
+			tt.Want.Group = group.Name
+			if !reflect.DeepEqual(got, tt.Want) {
+				t.Errorf("expected %+v, received %+v", tt.Want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package valuesink
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -20,7 +21,7 @@ type Sink struct {
 	value     interface{}
 	timestamp time.Time
 	err       error // Passes errors to WaitReady()
-	ready     *sync.WaitGroup
+	ready     chan struct{}
 	closed    bool
 }
 
@@ -35,7 +36,7 @@ func (s *Sink) Close() {
 	s.closed = true
 
 	if s.ready != nil {
-		s.ready.Done()
+		close(s.ready)
 		s.ready = nil
 	}
 }
@@ -67,8 +68,9 @@ func (s *Sink) Ready() (ready bool) {
 // Update provides an error, WaitReady unblocks and returns that error.
 //
 // If the sink does not contain a value and has been closed then ErrClosed will
-// be returned.
-func (s *Sink) WaitReady() (err error) {
+// be returned. If ctx is cancelled before the sink is ready then the context's
+// error will be returned.
+func (s *Sink) WaitReady(ctx context.Context) (err error) {
 	s.mutex.Lock()
 
 	if s.value != nil {
@@ -82,14 +84,17 @@ func (s *Sink) WaitReady() (err error) {
 	}
 
 	if s.ready == nil {
-		s.ready = new(sync.WaitGroup)
-		s.ready.Add(1)
+		s.ready = make(chan struct{})
 	}
 	rdy := s.ready
 
 	s.mutex.Unlock()
 
-	rdy.Wait()
+	select {
+	case <-rdy:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	s.mutex.RLock()
 	if s.value == nil && s.err == nil && s.closed {
@@ -126,7 +131,7 @@ func (s *Sink) Update(value interface{}, timestamp time.Time, err error) {
 	if s.ready != nil {
 		// This notifies callers waiting for the initial value to become available.
 		// This notification is sent even if err != nil.
-		s.ready.Done()
+		close(s.ready)
 		s.ready = nil
 	}
 }
@@ -0,0 +1,13 @@
+// +build !windows
+
+package versionvector
+
+import "github.com/go-ole/go-ole"
+
+func decodeSafeArray(sa *ole.SafeArrayConversion) (Values, error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+func encodeSafeArray(values Values) (*ole.SafeArrayConversion, error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
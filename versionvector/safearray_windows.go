@@ -0,0 +1,208 @@
+// +build windows
+
+package versionvector
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+var (
+	modoleaut32 = syscall.NewLazyDLL("oleaut32.dll")
+
+	procSafeArrayCreate       = modoleaut32.NewProc("SafeArrayCreate")
+	procSafeArrayCreateVector = modoleaut32.NewProc("SafeArrayCreateVector")
+	procSafeArrayDestroy      = modoleaut32.NewProc("SafeArrayDestroy")
+	procSafeArrayGetDim       = modoleaut32.NewProc("SafeArrayGetDim")
+	procSafeArrayGetLBound    = modoleaut32.NewProc("SafeArrayGetLBound")
+	procSafeArrayGetUBound    = modoleaut32.NewProc("SafeArrayGetUBound")
+	procSafeArrayGetVartype   = modoleaut32.NewProc("SafeArrayGetVartype")
+	procSafeArrayGetElement   = modoleaut32.NewProc("SafeArrayGetElement")
+	procSafeArrayPutElement   = modoleaut32.NewProc("SafeArrayPutElement")
+	procSafeArrayAccessData   = modoleaut32.NewProc("SafeArrayAccessData")
+	procSafeArrayUnaccessData = modoleaut32.NewProc("SafeArrayUnaccessData")
+)
+
+// decodeSafeArray decodes the reference version vectors returned by
+// GetReferenceVersionVectors.
+//
+// [MS-DFSRH]: 3.1.5.4.4
+//
+// The vectors are returned as a two-dimensional safe array of variants with
+// one row per replicated folder. The first element of each row is the GUID of
+// the replicated folder as a string and the second is a byte array containing
+// its VERSION_VECTOR.
+func decodeSafeArray(sa *ole.SafeArrayConversion) (values Values, err error) {
+	psa := sa.Array
+
+	var vt uint16
+	if err = hresult(procSafeArrayGetVartype.Call(uintptr(unsafe.Pointer(psa)), uintptr(unsafe.Pointer(&vt)))); err != nil {
+		return
+	}
+	dims, _, _ := procSafeArrayGetDim.Call(uintptr(unsafe.Pointer(psa)))
+	if ole.VT(vt) != ole.VT_VARIANT || dims != 2 {
+		return nil, ErrInvalidLayout
+	}
+
+	rowLow, rowHigh, err := bounds(psa, 1)
+	if err != nil {
+		return
+	}
+	colLow, colHigh, err := bounds(psa, 2)
+	if err != nil {
+		return
+	}
+	if colHigh-colLow != 1 {
+		return nil, ErrInvalidLayout
+	}
+
+	for row := rowLow; row <= rowHigh; row++ {
+		var folder FolderVector
+
+		id, err := element(psa, row, colLow)
+		if err != nil {
+			return nil, err
+		}
+		s := id.ToString()
+		ole.VariantClear(&id)
+		g := ole.NewGUID(s)
+		if g == nil {
+			return nil, ErrInvalidLayout
+		}
+		folder.Folder = *g
+
+		v, err := element(psa, row, colHigh)
+		if err != nil {
+			return nil, err
+		}
+		data, err := byteArray(&v)
+		ole.VariantClear(&v)
+		if err != nil {
+			return nil, err
+		}
+		if folder.Ranges, err = DecodeRanges(data); err != nil {
+			return nil, err
+		}
+
+		values = append(values, folder)
+	}
+	return
+}
+
+// encodeSafeArray encodes values in the layout expected by
+// GetReferenceBacklogCounts and GetReport.
+func encodeSafeArray(values Values) (sa *ole.SafeArrayConversion, err error) {
+	bounds := [2]ole.SafeArrayBound{
+		{Elements: uint32(len(values))},
+		{Elements: 2},
+	}
+	p, _, _ := procSafeArrayCreate.Call(uintptr(ole.VT_VARIANT), 2, uintptr(unsafe.Pointer(&bounds[0])))
+	if p == 0 {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	psa := *(**ole.SafeArray)(unsafe.Pointer(&p))
+
+	for row := range values {
+		id := ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocString(values[row].Folder.String())))))
+		err = put(psa, int32(row), 0, &id)
+		ole.VariantClear(&id)
+		if err != nil {
+			break
+		}
+
+		var data ole.VARIANT
+		data, err = byteVariant(EncodeRanges(values[row].Ranges))
+		if err != nil {
+			break
+		}
+		err = put(psa, int32(row), 1, &data)
+		ole.VariantClear(&data)
+		if err != nil {
+			break
+		}
+	}
+
+	if err != nil {
+		procSafeArrayDestroy.Call(uintptr(unsafe.Pointer(psa)))
+		return nil, err
+	}
+	return &ole.SafeArrayConversion{Array: psa}, nil
+}
+
+func bounds(psa *ole.SafeArray, dim uint32) (low, high int32, err error) {
+	if err = hresult(procSafeArrayGetLBound.Call(uintptr(unsafe.Pointer(psa)), uintptr(dim), uintptr(unsafe.Pointer(&low)))); err != nil {
+		return
+	}
+	err = hresult(procSafeArrayGetUBound.Call(uintptr(unsafe.Pointer(psa)), uintptr(dim), uintptr(unsafe.Pointer(&high))))
+	return
+}
+
+// element returns a copy of the variant at the given indices. The caller must
+// clear the returned variant.
+func element(psa *ole.SafeArray, row, col int32) (v ole.VARIANT, err error) {
+	indices := [2]int32{row, col}
+	err = hresult(procSafeArrayGetElement.Call(uintptr(unsafe.Pointer(psa)), uintptr(unsafe.Pointer(&indices[0])), uintptr(unsafe.Pointer(&v))))
+	return
+}
+
+func put(psa *ole.SafeArray, row, col int32, v *ole.VARIANT) error {
+	indices := [2]int32{row, col}
+	return hresult(procSafeArrayPutElement.Call(uintptr(unsafe.Pointer(psa)), uintptr(unsafe.Pointer(&indices[0])), uintptr(unsafe.Pointer(v))))
+}
+
+// byteArray copies the contents of a VT_ARRAY|VT_UI1 variant.
+func byteArray(v *ole.VARIANT) (data []byte, err error) {
+	if v.VT != ole.VT_ARRAY|ole.VT_UI1 {
+		return nil, ErrInvalidLayout
+	}
+	psa := *(**ole.SafeArray)(unsafe.Pointer(&v.Val))
+	low, high, err := bounds(psa, 1)
+	if err != nil {
+		return
+	}
+	n := int(high - low + 1)
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var p unsafe.Pointer
+	if err = hresult(procSafeArrayAccessData.Call(uintptr(unsafe.Pointer(psa)), uintptr(unsafe.Pointer(&p)))); err != nil {
+		return
+	}
+	defer procSafeArrayUnaccessData.Call(uintptr(unsafe.Pointer(psa)))
+
+	data = make([]byte, n)
+	copy(data, (*[1 << 30]byte)(p)[:n:n])
+	return
+}
+
+// byteVariant returns a VT_ARRAY|VT_UI1 variant containing a copy of data.
+// The caller must clear the returned variant.
+func byteVariant(data []byte) (v ole.VARIANT, err error) {
+	p, _, _ := procSafeArrayCreateVector.Call(uintptr(ole.VT_UI1), 0, uintptr(len(data)))
+	if p == 0 {
+		return v, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	psa := *(**ole.SafeArray)(unsafe.Pointer(&p))
+
+	if len(data) > 0 {
+		var dst unsafe.Pointer
+		if err = hresult(procSafeArrayAccessData.Call(uintptr(unsafe.Pointer(psa)), uintptr(unsafe.Pointer(&dst)))); err != nil {
+			procSafeArrayDestroy.Call(uintptr(unsafe.Pointer(psa)))
+			return
+		}
+		copy((*[1 << 30]byte)(dst)[:len(data):len(data)], data)
+		procSafeArrayUnaccessData.Call(uintptr(unsafe.Pointer(psa)))
+	}
+
+	return ole.NewVariant(ole.VT_ARRAY|ole.VT_UI1, int64(uintptr(unsafe.Pointer(psa)))), nil
+}
+
+func hresult(hr, _ uintptr, _ error) error {
+	if hr != 0 {
+		return ole.NewError(hr)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package versionvector
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/go-ole/go-ole"
+)
+
+// rangeSize is the size in bytes of an encoded FRS_VERSION_VECTOR entry.
+const rangeSize = 32
+
+var (
+	// ErrInvalidLength is returned when encoded version vector data has an
+	// invalid length.
+	ErrInvalidLength = errors.New("The version vector data has an invalid length.")
+	// ErrInvalidLayout is returned when a version vector safe array does not
+	// have the layout described by [MS-DFSRH].
+	ErrInvalidLayout = errors.New("The version vector safe array has an invalid layout.")
+	// ErrNoData is returned when a vector is neither backed by a safe array
+	// nor by native values.
+	ErrNoData = errors.New("The version vector does not contain any data.")
+)
+
+// Values is the native representation of the reference version vectors of a
+// replication group. It contains a version vector for each replicated folder
+// in the group, in the order in which they were returned by the member.
+type Values []FolderVector
+
+// FolderVector is the version vector of a replicated folder.
+type FolderVector struct {
+	Folder ole.GUID
+	Ranges []Range
+}
+
+// Range is a range of versions that originated from a database. It
+// corresponds to the FRS_VERSION_VECTOR structure of [MS-FRS2].
+type Range struct {
+	Database ole.GUID
+	Low      uint64
+	High     uint64
+}
+
+// Relation describes how one version vector relates to another.
+type Relation int
+
+// Version vector relations.
+const (
+	Equal      Relation = iota // Both vectors contain the same versions
+	Ahead                      // The vector contains all versions of the other, and more
+	Behind                     // The other vector contains all versions of this one, and more
+	Concurrent                 // Each vector contains versions missing from the other
+)
+
+// String returns a string representation of the relation.
+func (r Relation) String() string {
+	switch r {
+	case Equal:
+		return "equal"
+	case Ahead:
+		return "ahead"
+	case Behind:
+		return "behind"
+	default:
+		return "concurrent"
+	}
+}
+
+// Folder returns the version vector of the given replicated folder, or nil if
+// v does not contain it.
+func (v Values) Folder(folder ole.GUID) *FolderVector {
+	for i := range v {
+		if ole.IsEqualGUID(&v[i].Folder, &folder) {
+			return &v[i]
+		}
+	}
+	return nil
+}
+
+// Compare returns the relation of v to other across all replicated folders.
+// Folders that are missing from either side are treated as empty.
+func (v Values) Compare(other Values) Relation {
+	return relation(len(v.Diff(other)) == 0, len(other.Diff(v)) == 0)
+}
+
+// Diff returns the versions that are present in v but missing from other,
+// for each replicated folder that has any. This is the set of changes that a
+// member with vector other has yet to receive.
+func (v Values) Diff(other Values) (diff Values) {
+	for i := range v {
+		var ranges []Range
+		if o := other.Folder(v[i].Folder); o != nil {
+			ranges = v[i].Diff(*o)
+		} else {
+			ranges = normalize(v[i].Ranges)
+		}
+		if len(ranges) > 0 {
+			diff = append(diff, FolderVector{Folder: v[i].Folder, Ranges: ranges})
+		}
+	}
+	return
+}
+
+// Hash returns a hash of v that does not depend on the order of its folders
+// or ranges, or on how its ranges are split.
+func (v Values) Hash() (hash [sha256.Size]byte) {
+	folders := make(Values, len(v))
+	for i := range v {
+		folders[i] = FolderVector{Folder: v[i].Folder, Ranges: normalize(v[i].Ranges)}
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		return lessGUID(&folders[i].Folder, &folders[j].Folder)
+	})
+	data, _ := folders.MarshalBinary()
+	return sha256.Sum256(data)
+}
+
+// MarshalBinary encodes v. Each folder is encoded as its GUID, followed by
+// the number of ranges as a 32-bit integer and the ranges in FRS_VERSION_VECTOR
+// layout. All values are little-endian.
+func (v Values) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range v {
+		buf.Write(encodeGUID(&v[i].Folder))
+		binary.Write(&buf, binary.LittleEndian, uint32(len(v[i].Ranges)))
+		buf.Write(EncodeRanges(v[i].Ranges))
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes data produced by MarshalBinary.
+func (v *Values) UnmarshalBinary(data []byte) error {
+	var values Values
+	for len(data) > 0 {
+		if len(data) < 20 {
+			return ErrInvalidLength
+		}
+		folder := decodeGUID(data[0:16])
+		n := int(binary.LittleEndian.Uint32(data[16:20]))
+		data = data[20:]
+		if n < 0 || len(data) < n*rangeSize {
+			return ErrInvalidLength
+		}
+		ranges, err := DecodeRanges(data[:n*rangeSize])
+		if err != nil {
+			return err
+		}
+		data = data[n*rangeSize:]
+		values = append(values, FolderVector{Folder: folder, Ranges: ranges})
+	}
+	*v = values
+	return nil
+}
+
+// Compare returns the relation of f to other.
+func (f FolderVector) Compare(other FolderVector) Relation {
+	return relation(len(f.Diff(other)) == 0, len(other.Diff(f)) == 0)
+}
+
+// Diff returns the versions that are present in f but missing from other.
+func (f FolderVector) Diff(other FolderVector) (diff []Range) {
+	a, b := normalize(f.Ranges), normalize(other.Ranges)
+	for _, r := range a {
+		remaining := []Range{r}
+		for _, s := range b {
+			if !ole.IsEqualGUID(&r.Database, &s.Database) {
+				continue
+			}
+			remaining = subtract(remaining, s)
+		}
+		diff = append(diff, remaining...)
+	}
+	return
+}
+
+// DecodeRanges decodes a VERSION_VECTOR, which is a sequence of
+// FRS_VERSION_VECTOR structures as described by [MS-FRS2].
+func DecodeRanges(data []byte) (ranges []Range, err error) {
+	if len(data)%rangeSize != 0 {
+		return nil, ErrInvalidLength
+	}
+	ranges = make([]Range, 0, len(data)/rangeSize)
+	for i := 0; i < len(data); i += rangeSize {
+		ranges = append(ranges, Range{
+			Database: decodeGUID(data[i : i+16]),
+			Low:      binary.LittleEndian.Uint64(data[i+16 : i+24]),
+			High:     binary.LittleEndian.Uint64(data[i+24 : i+32]),
+		})
+	}
+	return
+}
+
+// EncodeRanges encodes ranges as a VERSION_VECTOR, which is a sequence of
+// FRS_VERSION_VECTOR structures as described by [MS-FRS2].
+func EncodeRanges(ranges []Range) []byte {
+	data := make([]byte, len(ranges)*rangeSize)
+	for i, r := range ranges {
+		b := data[i*rangeSize:]
+		copy(b[0:16], encodeGUID(&r.Database))
+		binary.LittleEndian.PutUint64(b[16:24], r.Low)
+		binary.LittleEndian.PutUint64(b[24:32], r.High)
+	}
+	return data
+}
+
+func relation(subset, superset bool) Relation {
+	switch {
+	case subset && superset:
+		return Equal
+	case superset:
+		return Ahead
+	case subset:
+		return Behind
+	default:
+		return Concurrent
+	}
+}
+
+// normalize returns a sorted copy of ranges in which overlapping and adjacent
+// ranges from the same database have been merged.
+func normalize(ranges []Range) []Range {
+	sorted := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Low <= r.High {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !ole.IsEqualGUID(&sorted[i].Database, &sorted[j].Database) {
+			return lessGUID(&sorted[i].Database, &sorted[j].Database)
+		}
+		return sorted[i].Low < sorted[j].Low
+	})
+
+	var merged []Range
+	for _, r := range sorted {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if ole.IsEqualGUID(&last.Database, &r.Database) && (r.Low <= last.High || r.Low-last.High == 1) {
+				if r.High > last.High {
+					last.High = r.High
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// subtract removes the versions in s from each of the given ranges.
+func subtract(ranges []Range, s Range) (result []Range) {
+	for _, r := range ranges {
+		if s.High < r.Low || s.Low > r.High {
+			result = append(result, r)
+			continue
+		}
+		if s.Low > r.Low {
+			result = append(result, Range{Database: r.Database, Low: r.Low, High: s.Low - 1})
+		}
+		if s.High < r.High {
+			result = append(result, Range{Database: r.Database, Low: s.High + 1, High: r.High})
+		}
+	}
+	return
+}
+
+func encodeGUID(g *ole.GUID) []byte {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b[0:4], g.Data1)
+	binary.LittleEndian.PutUint16(b[4:6], g.Data2)
+	binary.LittleEndian.PutUint16(b[6:8], g.Data3)
+	copy(b[8:16], g.Data4[:])
+	return b
+}
+
+func decodeGUID(b []byte) (g ole.GUID) {
+	g.Data1 = binary.LittleEndian.Uint32(b[0:4])
+	g.Data2 = binary.LittleEndian.Uint16(b[4:6])
+	g.Data3 = binary.LittleEndian.Uint16(b[6:8])
+	copy(g.Data4[:], b[8:16])
+	return
+}
+
+func lessGUID(a, b *ole.GUID) bool {
+	return bytes.Compare(encodeGUID(a), encodeGUID(b)) < 0
+}
@@ -0,0 +1,185 @@
+package versionvector
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/go-ole/go-ole"
+)
+
+var (
+	db1     = ole.GUID{Data1: 1, Data2: 0x1111, Data3: 0x2222, Data4: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}
+	db2     = ole.GUID{Data1: 2, Data2: 0x1111, Data3: 0x2222, Data4: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}
+	folder1 = ole.GUID{Data1: 0x10}
+	folder2 = ole.GUID{Data1: 0x20}
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []Range
+		want []Range
+	}{
+		{"empty", nil, nil},
+		{"single", []Range{{db1, 1, 5}}, []Range{{db1, 1, 5}}},
+		{"overlapping", []Range{{db1, 1, 5}, {db1, 3, 8}}, []Range{{db1, 1, 8}}},
+		{"adjacent", []Range{{db1, 1, 5}, {db1, 6, 8}}, []Range{{db1, 1, 8}}},
+		{"contained", []Range{{db1, 1, 10}, {db1, 3, 4}}, []Range{{db1, 1, 10}}},
+		{"gap", []Range{{db1, 7, 8}, {db1, 1, 5}}, []Range{{db1, 1, 5}, {db1, 7, 8}}},
+		{"databases", []Range{{db2, 1, 5}, {db1, 6, 8}, {db2, 6, 7}}, []Range{{db1, 6, 8}, {db2, 1, 7}}},
+		{"inverted", []Range{{db1, 5, 1}, {db1, 9, 9}}, []Range{{db1, 9, 9}}},
+	}
+	for _, tt := range tests {
+		if got := normalize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: normalize(%v) = %v, want %v", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFolderDiff(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  []Range
+		want  []Range
+		order Relation
+	}{
+		{"equal", []Range{{db1, 1, 10}}, []Range{{db1, 1, 5}, {db1, 6, 10}}, nil, Equal},
+		{"ahead", []Range{{db1, 1, 10}}, []Range{{db1, 1, 4}}, []Range{{db1, 5, 10}}, Ahead},
+		{"behind", []Range{{db1, 1, 4}}, []Range{{db1, 1, 10}}, nil, Behind},
+		{"hole", []Range{{db1, 1, 10}}, []Range{{db1, 1, 3}, {db1, 6, 10}}, []Range{{db1, 4, 5}}, Ahead},
+		{"other database", []Range{{db1, 1, 3}}, []Range{{db2, 1, 3}}, []Range{{db1, 1, 3}}, Concurrent},
+		{"concurrent", []Range{{db1, 1, 5}, {db2, 1, 2}}, []Range{{db1, 1, 8}}, []Range{{db2, 1, 2}}, Concurrent},
+		{"empty", nil, nil, nil, Equal},
+	}
+	for _, tt := range tests {
+		a := FolderVector{Folder: folder1, Ranges: tt.a}
+		b := FolderVector{Folder: folder1, Ranges: tt.b}
+		if got := a.Diff(b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Diff = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := a.Compare(b); got != tt.order {
+			t.Errorf("%s: Compare = %s, want %s", tt.name, got, tt.order)
+		}
+	}
+}
+
+func TestValuesCompare(t *testing.T) {
+	base := Values{
+		{Folder: folder1, Ranges: []Range{{db1, 1, 10}}},
+		{Folder: folder2, Ranges: []Range{{db2, 1, 5}}},
+	}
+	tests := []struct {
+		name  string
+		other Values
+		want  Relation
+		diff  Values
+	}{
+		{"equal", Values{
+			{Folder: folder2, Ranges: []Range{{db2, 1, 5}}},
+			{Folder: folder1, Ranges: []Range{{db1, 1, 4}, {db1, 5, 10}}},
+		}, Equal, nil},
+		{"missing folder", Values{
+			{Folder: folder1, Ranges: []Range{{db1, 1, 10}}},
+		}, Ahead, Values{{Folder: folder2, Ranges: []Range{{db2, 1, 5}}}}},
+		{"behind", Values{
+			{Folder: folder1, Ranges: []Range{{db1, 1, 12}}},
+			{Folder: folder2, Ranges: []Range{{db2, 1, 5}}},
+		}, Behind, nil},
+		{"concurrent", Values{
+			{Folder: folder1, Ranges: []Range{{db1, 1, 12}}},
+		}, Concurrent, Values{{Folder: folder2, Ranges: []Range{{db2, 1, 5}}}}},
+	}
+	for _, tt := range tests {
+		if got := base.Compare(tt.other); got != tt.want {
+			t.Errorf("%s: Compare = %s, want %s", tt.name, got, tt.want)
+		}
+		if got := base.Diff(tt.other); !reflect.DeepEqual(got, tt.diff) {
+			t.Errorf("%s: Diff = %v, want %v", tt.name, got, tt.diff)
+		}
+	}
+}
+
+func TestRangesRoundTrip(t *testing.T) {
+	tests := [][]Range{
+		{},
+		{{db1, 0, 0}},
+		{{db1, 1, 10}, {db2, 1 << 40, 1<<64 - 1}},
+	}
+	for _, ranges := range tests {
+		data := EncodeRanges(ranges)
+		if len(data) != len(ranges)*rangeSize {
+			t.Errorf("len(EncodeRanges(%v)) = %d, want %d", ranges, len(data), len(ranges)*rangeSize)
+		}
+		got, err := DecodeRanges(data)
+		if err != nil {
+			t.Fatalf("DecodeRanges: %v", err)
+		}
+		if !reflect.DeepEqual(got, ranges) {
+			t.Errorf("DecodeRanges(EncodeRanges(%v)) = %v", ranges, got)
+		}
+	}
+
+	if _, err := DecodeRanges(make([]byte, rangeSize+1)); err != ErrInvalidLength {
+		t.Errorf("DecodeRanges of truncated data: %v, want ErrInvalidLength", err)
+	}
+}
+
+func TestEncodeRangesLayout(t *testing.T) {
+	data := EncodeRanges([]Range{{db1, 0x0102, 0x0304}})
+	want := []byte{
+		1, 0, 0, 0, 0x11, 0x11, 0x22, 0x22, 1, 2, 3, 4, 5, 6, 7, 8, // Database GUID
+		0x02, 0x01, 0, 0, 0, 0, 0, 0, // Low
+		0x04, 0x03, 0, 0, 0, 0, 0, 0, // High
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("EncodeRanges = %x, want %x", data, want)
+	}
+}
+
+func TestValuesMarshalBinary(t *testing.T) {
+	v := Values{
+		{Folder: folder1, Ranges: []Range{{db1, 1, 10}, {db2, 3, 4}}},
+		{Folder: folder2},
+	}
+	data, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2*20 + 2*rangeSize; len(data) != want {
+		t.Errorf("len(MarshalBinary) = %d, want %d", len(data), want)
+	}
+
+	var got Values
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if len(got) != 2 || !reflect.DeepEqual(got[0], v[0]) || got[1].Folder != folder2 || len(got[1].Ranges) != 0 {
+		t.Errorf("UnmarshalBinary(MarshalBinary(%v)) = %v", v, got)
+	}
+
+	if err := got.UnmarshalBinary(data[:len(data)-1]); err != ErrInvalidLength {
+		t.Errorf("UnmarshalBinary of truncated data: %v, want ErrInvalidLength", err)
+	}
+}
+
+func TestValuesHash(t *testing.T) {
+	a := Values{
+		{Folder: folder1, Ranges: []Range{{db1, 1, 10}}},
+		{Folder: folder2, Ranges: []Range{{db2, 1, 5}}},
+	}
+	b := Values{
+		{Folder: folder2, Ranges: []Range{{db2, 1, 2}, {db2, 3, 5}}},
+		{Folder: folder1, Ranges: []Range{{db1, 6, 10}, {db1, 1, 5}}},
+	}
+	c := Values{
+		{Folder: folder1, Ranges: []Range{{db1, 1, 11}}},
+		{Folder: folder2, Ranges: []Range{{db2, 1, 5}}},
+	}
+	if a.Hash() != b.Hash() {
+		t.Error("Hash depends on the order or splitting of ranges")
+	}
+	if a.Hash() == c.Hash() {
+		t.Error("Hash does not distinguish different versions")
+	}
+}
@@ -6,8 +6,14 @@ import (
 )
 
 // Vector represents version vector data from a replication group member.
+//
+// A vector is usually backed by a safe array returned from the DFSR Helper
+// protocol. Vectors created by NewFromValue are instead backed by an arbitrary
+// in-memory value, which allows simulated reporters to exchange vectors
+// without relying on the component object model.
 type Vector struct {
-	sa *ole.SafeArrayConversion
+	sa    *ole.SafeArrayConversion
+	value interface{}
 }
 
 // New returns a new version vector for the given safe array of data.
@@ -17,14 +23,64 @@ func New(sa *ole.SafeArrayConversion) (vector *Vector, err error) {
 	}, nil
 }
 
-// Data returns the version vector data as a safe array.
+// NewFromValue returns a new version vector that is backed by the given value
+// instead of a safe array. The value should be immutable, as it is shared by
+// all duplicates of the vector.
+func NewFromValue(value interface{}) *Vector {
+	return &Vector{
+		value: value,
+	}
+}
+
+// NewFromValues returns a new version vector that is backed by the given
+// native values.
+func NewFromValues(values Values) *Vector {
+	return NewFromValue(values)
+}
+
+// Encode returns a new version vector that is backed by a safe array
+// containing the given values, in the layout expected by the DFSR Helper
+// protocol. It is only supported on Windows.
+func Encode(values Values) (vector *Vector, err error) {
+	sa, err := encodeSafeArray(values)
+	if err != nil {
+		return
+	}
+	return New(sa)
+}
+
+// Data returns the version vector data as a safe array. It returns nil for
+// vectors created by NewFromValue.
 func (vector *Vector) Data() (sa *ole.SafeArrayConversion) {
 	return vector.sa
 }
 
+// Value returns the value of vectors created by NewFromValue. It returns nil
+// for vectors that are backed by a safe array.
+func (vector *Vector) Value() interface{} {
+	return vector.value
+}
+
+// Values returns the native representation of the vector. Vectors backed by a
+// safe array are decoded according to [MS-DFSRH], which is only supported on
+// Windows.
+func (vector *Vector) Values() (values Values, err error) {
+	if vector.sa != nil {
+		return decodeSafeArray(vector.sa)
+	}
+	if values, ok := vector.value.(Values); ok {
+		return values, nil
+	}
+	return nil, ErrNoData
+}
+
 // Duplicate will return a duplicate of the vector that does not share any
 // memory with the original.
 func (vector *Vector) Duplicate() (duplicate *Vector, err error) {
+	if vector.sa == nil {
+		return NewFromValue(vector.value), nil
+	}
+
 	sa, err := comutil.SafeArrayCopy(vector.Data().Array)
 	if err != nil {
 		return